@@ -7,13 +7,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
 )
 
 // AddSSHKeys the AddSSHKeys command is a special step that adds SSH keys from a project’s settings to a container. Also configures SSH to use these keys.
 type AddSSHKeys struct {
 	Name       string                `json:"name"`
 	Parameters *AddSSHKeysParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *AddSSHKeys) MarshalJSON() ([]byte, error) {
@@ -50,8 +56,7 @@ func (r *AddSSHKeys) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *AddSSHKeys) UnmarshalJSON(b []byte) error {
@@ -61,30 +66,42 @@ func (r *AddSSHKeys) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *AddSSHKeys) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *AddSSHKeys) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // AddSSHKeysParameters command parameters for the AddSSHKeys command.
@@ -94,6 +111,9 @@ type AddSSHKeysParameters struct {
 
 	// Title of the step to be shown in the CircleCI UI (default: full command)
 	Name string `json:"name,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *AddSSHKeysParameters) MarshalJSON() ([]byte, error) {
@@ -114,20 +134,21 @@ func (r *AddSSHKeysParameters) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *AddSSHKeysParameters) UnmarshalJSON(b []byte) error {
@@ -136,25 +157,37 @@ func (r *AddSSHKeysParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "fingerprints":
-			if err := json.Unmarshal([]byte(v), &r.Fingerprints); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Fingerprints); err != nil {
+				errs.addAt(k, err)
 			}
 			fingerprintsReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "fingerprints", "name"))
 		}
 	}
 	// check if fingerprints (a required property) was received
 	if !fingerprintsReceived {
-		return errors.New("\"fingerprints\" is required but was not present")
+		errs.add(missingField("fingerprints"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *AddSSHKeysParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *AddSSHKeysParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Attach special step used to attach the workflow’s workspace to the current container.
@@ -163,6 +196,9 @@ func (r *AddSSHKeysParameters) UnmarshalJSON(b []byte) error {
 type Attach struct {
 	Name       string            `json:"name"`
 	Parameters *AttachParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Attach) MarshalJSON() ([]byte, error) {
@@ -199,8 +235,7 @@ func (r *Attach) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Attach) UnmarshalJSON(b []byte) error {
@@ -210,30 +245,42 @@ func (r *Attach) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Attach) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Attach) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // AttachParameters command parameters for the attach command.
@@ -243,6 +290,9 @@ type AttachParameters struct {
 
 	// Title of the step to be shown in the CircleCI UI (default: full command)
 	Name string `json:"name,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *AttachParameters) MarshalJSON() ([]byte, error) {
@@ -263,20 +313,21 @@ func (r *AttachParameters) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *AttachParameters) UnmarshalJSON(b []byte) error {
@@ -285,34 +336,80 @@ func (r *AttachParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "at":
-			if err := json.Unmarshal([]byte(v), &r.At); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.At); err != nil {
+				errs.addAt(k, err)
 			}
 			atReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "at", "name"))
 		}
 	}
 	// check if at (a required property) was received
 	if !atReceived {
-		return errors.New("\"at\" is required but was not present")
+		errs.add(missingField("at"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *AttachParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *AttachParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Branches a map defining rules for execution on specific branches.
 type Branches struct {
 	// Either a single branch specifier, or a list of branch specifiers
-	Ignore []string `json:"ignore,omitempty"`
+	Ignore StringOrList `json:"ignore,omitempty"`
 
 	// Either a single branch specifier, or a list of branch specifiers
-	Only []string `json:"only,omitempty"`
+	Only StringOrList `json:"only,omitempty"`
+
+	unknownFields
+}
+
+func (r *Branches) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "ignore":
+			if err := unmarshalValue(v, &r.Ignore); err != nil {
+				errs.addAt(k, err)
+			}
+		case "only":
+			if err := unmarshalValue(v, &r.Only); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "ignore", "only"))
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Branches) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Branches) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Checkout A special step used to check out source code to the configured path.
@@ -320,6 +417,9 @@ type Branches struct {
 type Checkout struct {
 	Name       string             `json:"name"`
 	Parameters *CheckoutParameter `json:"parameters,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Checkout) MarshalJSON() ([]byte, error) {
@@ -340,20 +440,21 @@ func (r *Checkout) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "parameters" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"parameters\": ")
-	if tmp, err := json.Marshal(r.Parameters); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Checkout) UnmarshalJSON(b []byte) error {
@@ -362,25 +463,37 @@ func (r *Checkout) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Checkout) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Checkout) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // CheckoutParameter command parameters for the checkout command.
@@ -395,10 +508,13 @@ type CheckoutParameter struct {
 
 // CircleCIConfigObject CircleCI configuration object
 type CircleCIConfigObject struct {
-	Commands  []interface{} `json:"commands,omitempty"`
-	Jobs      []*Job        `json:"jobs,omitempty"`
-	Version   float64       `json:"version"`
-	Workflows []*Workflow   `json:"workflows,omitempty"`
+	Commands  []*CustomCommand `json:"commands,omitempty"`
+	Jobs      []*Job           `json:"jobs,omitempty"`
+	Version   float64          `json:"version"`
+	Workflows []*Workflow      `json:"workflows,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *CircleCIConfigObject) MarshalJSON() ([]byte, error) {
@@ -406,27 +522,31 @@ func (r *CircleCIConfigObject) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "commands" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"commands\": ")
-	if tmp, err := json.Marshal(r.Commands); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Commands) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"commands\": ")
+		if tmp, err := json.Marshal(r.Commands); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "jobs" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"jobs\": ")
-	if tmp, err := json.Marshal(r.Jobs); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Jobs) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"jobs\": ")
+		if tmp, err := json.Marshal(r.Jobs); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Version" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "version" field
@@ -441,20 +561,21 @@ func (r *CircleCIConfigObject) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "workflows" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"workflows\": ")
-	if tmp, err := json.Marshal(r.Workflows); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Workflows) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"workflows\": ")
+		if tmp, err := json.Marshal(r.Workflows); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *CircleCIConfigObject) UnmarshalJSON(b []byte) error {
@@ -463,33 +584,45 @@ func (r *CircleCIConfigObject) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "commands":
-			if err := json.Unmarshal([]byte(v), &r.Commands); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Commands); err != nil {
+				errs.addAt(k, err)
 			}
 		case "jobs":
-			if err := json.Unmarshal([]byte(v), &r.Jobs); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Jobs); err != nil {
+				errs.addAt(k, err)
 			}
 		case "version":
-			if err := json.Unmarshal([]byte(v), &r.Version); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Version); err != nil {
+				errs.addAt(k, err)
 			}
 			versionReceived = true
 		case "workflows":
-			if err := json.Unmarshal([]byte(v), &r.Workflows); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Workflows); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "commands", "jobs", "version", "workflows"))
 		}
 	}
 	// check if version (a required property) was received
 	if !versionReceived {
-		return errors.New("\"version\" is required but was not present")
+		errs.add(missingField("version"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *CircleCIConfigObject) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *CircleCIConfigObject) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // CommandParameters parameter definitions for the command.
@@ -498,6 +631,9 @@ type CommandParameters struct {
 
 	// Title of the step to be shown in the CircleCI UI (default: full command)
 	Name string `json:"name,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *CommandParameters) MarshalJSON() ([]byte, error) {
@@ -505,22 +641,35 @@ func (r *CommandParameters) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal any additional Properties, sorted by key
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
 	}
-	comma = true
-	// Marshal any additional Properties
-	for k, v := range r.AdditionalProperties {
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.AdditionalProperties[k]
 		if comma {
 			buf.WriteString(",")
 		}
-		buf.WriteString(fmt.Sprintf("\"%s\":", k))
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
 		if tmp, err := json.Marshal(v); err != nil {
 			return nil, err
 		} else {
@@ -530,8 +679,7 @@ func (r *CommandParameters) MarshalJSON() ([]byte, error) {
 	}
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *CommandParameters) UnmarshalJSON(b []byte) error {
@@ -539,18 +687,20 @@ func (r *CommandParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		default:
 			// an additional "interface{}" value
 			var additionalValue interface{}
-			if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-				return err // invalid additionalProperty
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
 			if r.AdditionalProperties == nil {
 				r.AdditionalProperties = make(map[string]interface{})
@@ -558,40 +708,131 @@ func (r *CommandParameters) UnmarshalJSON(b []byte) error {
 			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *CommandParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *CommandParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // ConfigOrbImport orb import object.
+//
+// An orb is imported either from the registry, by a reference such as "circleci/node@5.0.2", or is defined inline.
 type ConfigOrbImport struct {
-	OrbAlias  string `json:"orbAlias"`
-	OrbImport string `json:"orbImport"`
+	// OrbAlias the alias the orb is imported under, used to refer to its elements as in "node/install".
+	// It is the key of the orb in the orbs section, and is not part of the encoding of the import.
+	OrbAlias string `json:"-"`
+
+	// OrbImport the reference of an orb from the registry, empty for an inline orb.
+	OrbImport string `json:"-"`
+
+	// Inline the definition of an inline orb, nil for an orb from the registry.
+	Inline *InlineOrbSchema `json:"-"`
+
+	unknownFields
 }
 
 func (r *ConfigOrbImport) MarshalJSON() ([]byte, error) {
+	if r.Inline != nil {
+		return json.Marshal(r.Inline)
+	}
+	return json.Marshal(r.OrbImport)
+}
+
+func (r *ConfigOrbImport) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v.(type) {
+	case string:
+		return json.Unmarshal(b, &r.OrbImport)
+	case map[string]interface{}:
+		return r.keepUnknownFields(unmarshalValue(b, &r.Inline))
+	}
+	return &TypeMismatchError{Expected: "string or object", Actual: jsonValueType(v)}
+}
+
+func (r *ConfigOrbImport) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *ConfigOrbImport) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// CustomCommand a reusable command, invoked as a single step by jobs and other commands.
+type CustomCommand struct {
+	Description string `json:"description,omitempty"`
+
+	// the name of the command, used to invoke it.
+	Name string `json:"name"`
+
+	// the parameters accepted by the command.
+	Parameters *ParameterSchema `json:"parameters,omitempty"`
+
+	// the steps run when the command is invoked.
+	Steps Steps `json:"steps"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *CustomCommand) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// "OrbAlias" field is required
+	// Marshal the "description" field
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// "Name" field is required
 	// only required object types supported for marshal checking (for now)
-	// Marshal the "orbAlias" field
+	// Marshal the "name" field
 	if comma {
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"orbAlias\": ")
-	if tmp, err := json.Marshal(r.OrbAlias); err != nil {
+	buf.WriteString("\"name\": ")
+	if tmp, err := json.Marshal(r.Name); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
 	}
 	comma = true
-	// "OrbImport" field is required
+	// Marshal the "parameters" field
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// "Steps" field is required
 	// only required object types supported for marshal checking (for now)
-	// Marshal the "orbImport" field
+	// Marshal the "steps" field
 	if comma {
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"orbImport\": ")
-	if tmp, err := json.Marshal(r.OrbImport); err != nil {
+	buf.WriteString("\"steps\": ")
+	if tmp, err := json.Marshal(r.Steps); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
@@ -599,41 +840,60 @@ func (r *ConfigOrbImport) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
-func (r *ConfigOrbImport) UnmarshalJSON(b []byte) error {
-	orbAliasReceived := false
-	orbImportReceived := false
+func (r *CustomCommand) UnmarshalJSON(b []byte) error {
+	nameReceived := false
+	stepsReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
-		case "orbAlias":
-			if err := json.Unmarshal([]byte(v), &r.OrbAlias); err != nil {
-				return err
+		case "description":
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
+			}
+		case "name":
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
+			}
+			nameReceived = true
+		case "parameters":
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
-			orbAliasReceived = true
-		case "orbImport":
-			if err := json.Unmarshal([]byte(v), &r.OrbImport); err != nil {
-				return err
+		case "steps":
+			if err := unmarshalValue(v, &r.Steps); err != nil {
+				errs.addAt(k, err)
 			}
-			orbImportReceived = true
+			stepsReceived = true
+		default:
+			errs.add(unknownField(k, "description", "name", "parameters", "steps"))
 		}
 	}
-	// check if orbAlias (a required property) was received
-	if !orbAliasReceived {
-		return errors.New("\"orbAlias\" is required but was not present")
+	// check if name (a required property) was received
+	if !nameReceived {
+		errs.add(missingField("name"))
 	}
-	// check if orbImport (a required property) was received
-	if !orbImportReceived {
-		return errors.New("\"orbImport\" is required but was not present")
+	// check if steps (a required property) was received
+	if !stepsReceived {
+		errs.add(missingField("steps"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *CustomCommand) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *CustomCommand) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // DockerAuth authentication for registries using standard `docker login` credentials.
@@ -642,6 +902,9 @@ type DockerAuth struct {
 	Password string `json:"password"`
 
 	Username string `json:"username"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerAuth) MarshalJSON() ([]byte, error) {
@@ -676,8 +939,7 @@ func (r *DockerAuth) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerAuth) UnmarshalJSON(b []byte) error {
@@ -687,107 +949,166 @@ func (r *DockerAuth) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "password":
-			if err := json.Unmarshal([]byte(v), &r.Password); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Password); err != nil {
+				errs.addAt(k, err)
 			}
 			passwordReceived = true
 		case "username":
-			if err := json.Unmarshal([]byte(v), &r.Username); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Username); err != nil {
+				errs.addAt(k, err)
 			}
 			usernameReceived = true
+		default:
+			errs.add(unknownField(k, "password", "username"))
 		}
 	}
 	// check if password (a required property) was received
 	if !passwordReceived {
-		return errors.New("\"password\" is required but was not present")
+		errs.add(missingField("password"))
 	}
 	// check if username (a required property) was received
 	if !usernameReceived {
-		return errors.New("\"username\" is required but was not present")
+		errs.add(missingField("username"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
 }
 
-// DockerAuthAWS authentication for AWS Elastic Container Registry (ECR).
-type DockerAuthAWS struct {
-	AwsAccessKeyId string `json:"aws_access_key_id"`
+func (r *DockerAuth) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerAuth) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// DockerAuthAWS authentication for AWS Elastic Container Registry (ECR), either with an access key or with the role
+// assumed through OpenID Connect.
+type DockerAuthAWS struct {
+	AwsAccessKeyId string `json:"aws_access_key_id,omitempty"`
 
 	// Specify an environment variable (e.g. $ECR_AWS_SECRET_ACCESS_KEY)
-	AwsSecretAccessKey string `json:"aws_secret_access_key"`
+	AwsSecretAccessKey string `json:"aws_secret_access_key,omitempty"`
+
+	// The ARN of the IAM role to assume with the OpenID Connect token of the job.
+	OidcRoleArn string `json:"oidc_role_arn,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerAuthAWS) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// "AwsAccessKeyId" field is required
-	// only required object types supported for marshal checking (for now)
 	// Marshal the "aws_access_key_id" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"aws_access_key_id\": ")
-	if tmp, err := json.Marshal(r.AwsAccessKeyId); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.AwsAccessKeyId != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"aws_access_key_id\": ")
+		if tmp, err := json.Marshal(r.AwsAccessKeyId); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// "AwsSecretAccessKey" field is required
-	// only required object types supported for marshal checking (for now)
 	// Marshal the "aws_secret_access_key" field
-	if comma {
-		buf.WriteString(",")
+	if r.AwsSecretAccessKey != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"aws_secret_access_key\": ")
+		if tmp, err := json.Marshal(r.AwsSecretAccessKey); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"aws_secret_access_key\": ")
-	if tmp, err := json.Marshal(r.AwsSecretAccessKey); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "oidc_role_arn" field
+	if r.OidcRoleArn != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"oidc_role_arn\": ")
+		if tmp, err := json.Marshal(r.OidcRoleArn); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerAuthAWS) UnmarshalJSON(b []byte) error {
 	aws_access_key_idReceived := false
 	aws_secret_access_keyReceived := false
+	oidc_role_arnReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "aws_access_key_id":
-			if err := json.Unmarshal([]byte(v), &r.AwsAccessKeyId); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.AwsAccessKeyId); err != nil {
+				errs.addAt(k, err)
 			}
 			aws_access_key_idReceived = true
 		case "aws_secret_access_key":
-			if err := json.Unmarshal([]byte(v), &r.AwsSecretAccessKey); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.AwsSecretAccessKey); err != nil {
+				errs.addAt(k, err)
 			}
 			aws_secret_access_keyReceived = true
+		case "oidc_role_arn":
+			if err := unmarshalValue(v, &r.OidcRoleArn); err != nil {
+				errs.addAt(k, err)
+			}
+			oidc_role_arnReceived = true
+		default:
+			errs.add(unknownField(k, "aws_access_key_id", "aws_secret_access_key", "oidc_role_arn"))
+		}
+	}
+	// oidc_role_arn replaces the access key
+	if oidc_role_arnReceived {
+		switch {
+		case aws_access_key_idReceived:
+			errs.add(conflictingKeys("aws_access_key_id", "oidc_role_arn"))
+		case aws_secret_access_keyReceived:
+			errs.add(conflictingKeys("aws_secret_access_key", "oidc_role_arn"))
 		}
+		return r.keepUnknownFields(errs.err())
 	}
 	// check if aws_access_key_id (a required property) was received
 	if !aws_access_key_idReceived {
-		return errors.New("\"aws_access_key_id\" is required but was not present")
+		errs.add(missingField("aws_access_key_id"))
 	}
 	// check if aws_secret_access_key (a required property) was received
 	if !aws_secret_access_keyReceived {
-		return errors.New("\"aws_secret_access_key\" is required but was not present")
+		errs.add(missingField("aws_secret_access_key"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *DockerAuthAWS) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerAuthAWS) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // DockerExecutor a docker based CircleCI executor.
@@ -803,6 +1124,9 @@ type DockerExecutor struct {
 	// Add additional Docker images which will be accessible from the primary container.
 	// This is typically used for adding a database as a service container.
 	ServiceImages []*DockerImage `json:"serviceImages"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerExecutor) MarshalJSON() ([]byte, error) {
@@ -810,16 +1134,18 @@ func (r *DockerExecutor) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "description" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"description\": ")
-	if tmp, err := json.Marshal(r.Description); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Image" field is required
 	if r.Image == nil {
 		return nil, errors.New("image is a required field")
@@ -863,8 +1189,7 @@ func (r *DockerExecutor) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerExecutor) UnmarshalJSON(b []byte) error {
@@ -875,55 +1200,70 @@ func (r *DockerExecutor) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "description":
-			if err := json.Unmarshal([]byte(v), &r.Description); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
 			}
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
 			imageReceived = true
 		case "resourceClass":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
 			}
 			resourceClassReceived = true
 		case "serviceImages":
-			if err := json.Unmarshal([]byte(v), &r.ServiceImages); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ServiceImages); err != nil {
+				errs.addAt(k, err)
 			}
 			serviceImagesReceived = true
+		default:
+			errs.add(unknownField(k, "description", "image", "resourceClass", "serviceImages"))
 		}
 	}
 	// check if image (a required property) was received
 	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
+		errs.add(missingField("image"))
 	}
 	// check if resourceClass (a required property) was received
 	if !resourceClassReceived {
-		return errors.New("\"resourceClass\" is required but was not present")
+		errs.add(missingField("resourceClass"))
 	}
 	// check if serviceImages (a required property) was received
 	if !serviceImagesReceived {
-		return errors.New("\"serviceImages\" is required but was not present")
+		errs.add(missingField("serviceImages"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *DockerExecutor) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerExecutor) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // DockerImage
 type DockerImage struct {
-	Auth        *DockerAuth       `json:"auth,omitempty"`
-	AwsAuth     *DockerAuthAWS    `json:"aws_auth,omitempty"`
-	Command     []string          `json:"command,omitempty"`
-	Entrypoint  []string          `json:"entrypoint,omitempty"`
-	Environment map[string]string `json:"environment,omitempty"`
-	Image       string            `json:"image"`
-	Name        string            `json:"name,omitempty"`
-	User        string            `json:"user,omitempty"`
+	Auth        *DockerAuth    `json:"auth,omitempty"`
+	AwsAuth     *DockerAuthAWS `json:"aws_auth,omitempty"`
+	Command     StringOrList   `json:"command,omitempty"`
+	Entrypoint  StringOrList   `json:"entrypoint,omitempty"`
+	Environment Environment    `json:"environment,omitempty"`
+	Image       string         `json:"image"`
+	Name        string         `json:"name,omitempty"`
+	User        string         `json:"user,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerImage) MarshalJSON() ([]byte, error) {
@@ -931,60 +1271,70 @@ func (r *DockerImage) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "auth" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"auth\": ")
-	if tmp, err := json.Marshal(r.Auth); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Auth != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"auth\": ")
+		if tmp, err := json.Marshal(r.Auth); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "aws_auth" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"aws_auth\": ")
-	if tmp, err := json.Marshal(r.AwsAuth); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.AwsAuth != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"aws_auth\": ")
+		if tmp, err := json.Marshal(r.AwsAuth); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "command" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"command\": ")
-	if tmp, err := json.Marshal(r.Command); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Command) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"command\": ")
+		if tmp, err := json.Marshal(r.Command); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "entrypoint" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"entrypoint\": ")
-	if tmp, err := json.Marshal(r.Entrypoint); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Entrypoint) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"entrypoint\": ")
+		if tmp, err := json.Marshal(r.Entrypoint); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "environment" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"environment\": ")
-	if tmp, err := json.Marshal(r.Environment); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Environment) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"environment\": ")
+		if tmp, err := json.Marshal(r.Environment); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Image" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "image" field
@@ -999,31 +1349,34 @@ func (r *DockerImage) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "user" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"user\": ")
-	if tmp, err := json.Marshal(r.User); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.User != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"user\": ")
+		if tmp, err := json.Marshal(r.User); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerImage) UnmarshalJSON(b []byte) error {
@@ -1032,54 +1385,69 @@ func (r *DockerImage) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "auth":
-			if err := json.Unmarshal([]byte(v), &r.Auth); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Auth); err != nil {
+				errs.addAt(k, err)
 			}
 		case "aws_auth":
-			if err := json.Unmarshal([]byte(v), &r.AwsAuth); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.AwsAuth); err != nil {
+				errs.addAt(k, err)
 			}
 		case "command":
-			if err := json.Unmarshal([]byte(v), &r.Command); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Command); err != nil {
+				errs.addAt(k, err)
 			}
 		case "entrypoint":
-			if err := json.Unmarshal([]byte(v), &r.Entrypoint); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Entrypoint); err != nil {
+				errs.addAt(k, err)
 			}
 		case "environment":
-			if err := json.Unmarshal([]byte(v), &r.Environment); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Environment); err != nil {
+				errs.addAt(k, err)
 			}
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
 			imageReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "user":
-			if err := json.Unmarshal([]byte(v), &r.User); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.User); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "auth", "aws_auth", "command", "entrypoint", "environment", "image", "name", "user"))
 		}
 	}
 	// check if image (a required property) was received
 	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
+		errs.add(missingField("image"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *DockerImage) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerImage) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // DockerImageMap
 type DockerImageMap struct {
 	Image string `json:"image"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerImageMap) MarshalJSON() ([]byte, error) {
@@ -1101,8 +1469,7 @@ func (r *DockerImageMap) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerImageMap) UnmarshalJSON(b []byte) error {
@@ -1111,21 +1478,90 @@ func (r *DockerImageMap) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
 			imageReceived = true
+		default:
+			errs.add(unknownField(k, "image"))
 		}
 	}
 	// check if image (a required property) was received
 	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
+		errs.add(missingField("image"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *DockerImageMap) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerImageMap) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// Environment environment variables, keyed by name.
+//
+// Values may be written as any scalar, as in `CGO_ENABLED: 0`; they are decoded as their string form.
+// A list of such objects is also accepted, and merged in order.
+type Environment map[string]string
+
+func (r *Environment) UnmarshalJSON(b []byte) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(b, &list); err == nil {
+		var errs Errors
+		env := make(Environment)
+		for i, item := range list {
+			var e Environment
+			if err := e.UnmarshalJSON(item); err != nil {
+				errs.addAt(strconv.Itoa(i), err)
+			}
+			for k, v := range e {
+				env[k] = v
+			}
+		}
+		*r = env
+		return errs.err()
+	}
+
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	var errs Errors
+	env := make(Environment, len(jsonMap))
+	for k, v := range jsonMap {
+		var value interface{}
+		if err := json.Unmarshal(v, &value); err != nil {
+			errs.addAt(k, err)
+			continue
+		}
+		switch value := value.(type) {
+		case string:
+			env[k] = value
+		case float64, bool:
+			env[k] = string(v)
+		default:
+			errs.addAt(k, &TypeMismatchError{Expected: "string", Actual: jsonValueType(value)})
+		}
 	}
-	return nil
+	*r = env
+	return errs.err()
+}
+
+func (r Environment) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Environment) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Git
@@ -1142,6 +1578,9 @@ type Git struct {
 
 	// The name of the git tag that was pushed to trigger the pipeline. If the pipeline was not triggered by a tag, then this is the empty string.
 	Tag string `json:"tag"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Git) MarshalJSON() ([]byte, error) {
@@ -1215,8 +1654,7 @@ func (r *Git) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Git) UnmarshalJSON(b []byte) error {
@@ -1229,69 +1667,84 @@ func (r *Git) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "base_revision":
-			if err := json.Unmarshal([]byte(v), &r.BaseRevision); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.BaseRevision); err != nil {
+				errs.addAt(k, err)
 			}
 			base_revisionReceived = true
 		case "branch":
-			if err := json.Unmarshal([]byte(v), &r.Branch); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Branch); err != nil {
+				errs.addAt(k, err)
 			}
 			branchReceived = true
 		case "_isLocal":
-			if err := json.Unmarshal([]byte(v), &r.IsLocal); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.IsLocal); err != nil {
+				errs.addAt(k, err)
 			}
 			_isLocalReceived = true
 		case "revision":
-			if err := json.Unmarshal([]byte(v), &r.Revision); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Revision); err != nil {
+				errs.addAt(k, err)
 			}
 			revisionReceived = true
 		case "tag":
-			if err := json.Unmarshal([]byte(v), &r.Tag); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Tag); err != nil {
+				errs.addAt(k, err)
 			}
 			tagReceived = true
+		default:
+			errs.add(unknownField(k, "base_revision", "branch", "_isLocal", "revision", "tag"))
 		}
 	}
 	// check if base_revision (a required property) was received
 	if !base_revisionReceived {
-		return errors.New("\"base_revision\" is required but was not present")
+		errs.add(missingField("base_revision"))
 	}
 	// check if branch (a required property) was received
 	if !branchReceived {
-		return errors.New("\"branch\" is required but was not present")
+		errs.add(missingField("branch"))
 	}
 	// check if _isLocal (a required property) was received
 	if !_isLocalReceived {
-		return errors.New("\"_isLocal\" is required but was not present")
+		errs.add(missingField("_isLocal"))
 	}
 	// check if revision (a required property) was received
 	if !revisionReceived {
-		return errors.New("\"revision\" is required but was not present")
+		errs.add(missingField("revision"))
 	}
 	// check if tag (a required property) was received
 	if !tagReceived {
-		return errors.New("\"tag\" is required but was not present")
+		errs.add(missingField("tag"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Git) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Git) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Job jobs define a collection of steps to be run within a given executor, and are orchestrated using workflows.
 type Job struct {
-	// the reusable executor to use for this job. The Executor must have already been instantiated and added to the config.
-	Executor interface{} `json:"executor"`
+	// the executor to use for this job. A *ReusedExecutor must refer to an executor that has already been instantiated and added to the config.
+	Executor Executor `json:"executor"`
 
 	// the name of the current Job.
 	Name string `json:"name"`
 
 	// a list of Commands to execute within the job in the order which they were added.
-	Steps []interface{} `json:"steps"`
+	Steps Steps `json:"steps"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Job) MarshalJSON() ([]byte, error) {
@@ -1299,7 +1752,9 @@ func (r *Job) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// "Executor" field is required
-	// only required object types supported for marshal checking (for now)
+	if r.Executor == nil {
+		return nil, errors.New("executor is a required field")
+	}
 	// Marshal the "executor" field
 	if comma {
 		buf.WriteString(",")
@@ -1339,8 +1794,7 @@ func (r *Job) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Job) UnmarshalJSON(b []byte) error {
@@ -1351,39 +1805,52 @@ func (r *Job) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "executor":
-			if err := json.Unmarshal([]byte(v), &r.Executor); err != nil {
-				return err
+			var err error
+			if r.Executor, err = decodeJobExecutor(v); err != nil {
+				errs.addAt(k, err)
 			}
 			executorReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "steps":
-			if err := json.Unmarshal([]byte(v), &r.Steps); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Steps); err != nil {
+				errs.addAt(k, err)
 			}
 			stepsReceived = true
+		default:
+			errs.add(unknownField(k, "executor", "name", "steps"))
 		}
 	}
 	// check if executor (a required property) was received
 	if !executorReceived {
-		return errors.New("\"executor\" is required but was not present")
+		errs.add(missingField("executor"))
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if steps (a required property) was received
 	if !stepsReceived {
-		return errors.New("\"steps\" is required but was not present")
+		errs.add(missingField("steps"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Job) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Job) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // MacOSExecutor a macOS virtual machine with configurable Xcode version.
@@ -1394,6 +1861,9 @@ type MacOSExecutor struct {
 
 	// Select an xcode version
 	Xcode string `json:"xcode"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *MacOSExecutor) MarshalJSON() ([]byte, error) {
@@ -1401,16 +1871,18 @@ func (r *MacOSExecutor) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "description" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"description\": ")
-	if tmp, err := json.Marshal(r.Description); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "ResourceClass" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "resourceClass" field
@@ -1439,8 +1911,7 @@ func (r *MacOSExecutor) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *MacOSExecutor) UnmarshalJSON(b []byte) error {
@@ -1450,85 +1921,137 @@ func (r *MacOSExecutor) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "description":
-			if err := json.Unmarshal([]byte(v), &r.Description); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
 			}
 		case "resourceClass":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
 			}
 			resourceClassReceived = true
 		case "xcode":
-			if err := json.Unmarshal([]byte(v), &r.Xcode); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Xcode); err != nil {
+				errs.addAt(k, err)
 			}
 			xcodeReceived = true
+		default:
+			errs.add(unknownField(k, "description", "resourceClass", "xcode"))
 		}
 	}
 	// check if resourceClass (a required property) was received
 	if !resourceClassReceived {
-		return errors.New("\"resourceClass\" is required but was not present")
+		errs.add(missingField("resourceClass"))
 	}
 	// check if xcode (a required property) was received
 	if !xcodeReceived {
-		return errors.New("\"xcode\" is required but was not present")
+		errs.add(missingField("xcode"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *MacOSExecutor) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *MacOSExecutor) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Machine
 type Machine struct {
-	Image string `json:"image"`
+	// DockerLayerCaching whether to enable docker layer caching: a bool, or a parameter expression string.
+	DockerLayerCaching interface{} `json:"docker_layer_caching,omitempty"`
+	Image              string      `json:"image,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Machine) MarshalJSON() ([]byte, error) {
+	// the zero value is written in its short form, "machine: true"
+	if r.DockerLayerCaching == nil && r.Image == "" {
+		return []byte("true"), nil
+	}
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// "Image" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "image" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the "docker_layer_caching" field
+	if r.DockerLayerCaching != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"docker_layer_caching\": ")
+		if tmp, err := json.Marshal(r.DockerLayerCaching); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"image\": ")
-	if tmp, err := json.Marshal(r.Image); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "image" field
+	if r.Image != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"image\": ")
+		if tmp, err := json.Marshal(r.Image); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Machine) UnmarshalJSON(b []byte) error {
-	imageReceived := false
+	// the short form, "machine: true", selects the default image
+	var enabled bool
+	if err := json.Unmarshal(b, &enabled); err == nil {
+		if !enabled {
+			return &InvalidValueError{Value: enabled, Reason: "machine must be true or an object"}
+		}
+		*r = Machine{}
+		return nil
+	}
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "docker_layer_caching":
+			if err := unmarshalBoolOrExpression(v, &r.DockerLayerCaching); err != nil {
+				errs.addAt(k, err)
+			}
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
-			imageReceived = true
+		default:
+			errs.add(unknownField(k, "docker_layer_caching", "image"))
 		}
 	}
-	// check if image (a required property) was received
-	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
-	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Machine) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Machine) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // MachineExecutor the linux virtual machine executor.
@@ -1538,6 +2061,9 @@ type MachineExecutor struct {
 	// Select one of the Ubuntu Linux VM Images provided by CircleCI.
 	Image         string `json:"image"`
 	ResourceClass string `json:"resourceClass"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *MachineExecutor) MarshalJSON() ([]byte, error) {
@@ -1545,16 +2071,18 @@ func (r *MachineExecutor) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "description" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"description\": ")
-	if tmp, err := json.Marshal(r.Description); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Image" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "image" field
@@ -1583,8 +2111,7 @@ func (r *MachineExecutor) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *MachineExecutor) UnmarshalJSON(b []byte) error {
@@ -1594,39 +2121,54 @@ func (r *MachineExecutor) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "description":
-			if err := json.Unmarshal([]byte(v), &r.Description); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
 			}
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
 			imageReceived = true
 		case "resourceClass":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
 			}
 			resourceClassReceived = true
+		default:
+			errs.add(unknownField(k, "description", "image", "resourceClass"))
 		}
 	}
 	// check if image (a required property) was received
 	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
+		errs.add(missingField("image"))
 	}
 	// check if resourceClass (a required property) was received
 	if !resourceClassReceived {
-		return errors.New("\"resourceClass\" is required but was not present")
+		errs.add(missingField("resourceClass"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *MachineExecutor) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *MachineExecutor) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Macos
 type Macos struct {
 	Xcode string `json:"xcode"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Macos) MarshalJSON() ([]byte, error) {
@@ -1648,8 +2190,7 @@ func (r *Macos) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Macos) UnmarshalJSON(b []byte) error {
@@ -1658,21 +2199,33 @@ func (r *Macos) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "xcode":
-			if err := json.Unmarshal([]byte(v), &r.Xcode); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Xcode); err != nil {
+				errs.addAt(k, err)
 			}
 			xcodeReceived = true
+		default:
+			errs.add(unknownField(k, "xcode"))
 		}
 	}
 	// check if xcode (a required property) was received
 	if !xcodeReceived {
-		return errors.New("\"xcode\" is required but was not present")
+		errs.add(missingField("xcode"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Macos) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Macos) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Persist special step used to persist the workflow’s workspace to the current container.
@@ -1681,6 +2234,9 @@ func (r *Macos) UnmarshalJSON(b []byte) error {
 type Persist struct {
 	Name       string             `json:"name"`
 	Parameters *PersistParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Persist) MarshalJSON() ([]byte, error) {
@@ -1717,8 +2273,7 @@ func (r *Persist) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Persist) UnmarshalJSON(b []byte) error {
@@ -1728,30 +2283,42 @@ func (r *Persist) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Persist) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Persist) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // PersistParameters command parameters for the persist command.
@@ -1764,6 +2331,9 @@ type PersistParameters struct {
 
 	// Either an absolute path or a path relative to `working_directory`
 	Root string `json:"root"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *PersistParameters) MarshalJSON() ([]byte, error) {
@@ -1771,16 +2341,18 @@ func (r *PersistParameters) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Paths" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "paths" field
@@ -1809,8 +2381,7 @@ func (r *PersistParameters) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *PersistParameters) UnmarshalJSON(b []byte) error {
@@ -1820,34 +2391,46 @@ func (r *PersistParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "paths":
-			if err := json.Unmarshal([]byte(v), &r.Paths); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Paths); err != nil {
+				errs.addAt(k, err)
 			}
 			pathsReceived = true
 		case "root":
-			if err := json.Unmarshal([]byte(v), &r.Root); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Root); err != nil {
+				errs.addAt(k, err)
 			}
 			rootReceived = true
+		default:
+			errs.add(unknownField(k, "name", "paths", "root"))
 		}
 	}
 	// check if paths (a required property) was received
 	if !pathsReceived {
-		return errors.New("\"paths\" is required but was not present")
+		errs.add(missingField("paths"))
 	}
 	// check if root (a required property) was received
 	if !rootReceived {
-		return errors.New("\"root\" is required but was not present")
+		errs.add(missingField("root"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *PersistParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *PersistParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Pipeline access pipeline variables from within CircleCI Cloud.
@@ -1863,6 +2446,9 @@ type Pipeline struct {
 
 	// Array of user defined parameters
 	Parameters []*PipelineParameter `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Pipeline) MarshalJSON() ([]byte, error) {
@@ -1923,8 +2509,7 @@ func (r *Pipeline) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Pipeline) UnmarshalJSON(b []byte) error {
@@ -1936,48 +2521,60 @@ func (r *Pipeline) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "id":
-			if err := json.Unmarshal([]byte(v), &r.Id); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Id); err != nil {
+				errs.addAt(k, err)
 			}
 			idReceived = true
 		case "_isLocal":
-			if err := json.Unmarshal([]byte(v), &r.IsLocal); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.IsLocal); err != nil {
+				errs.addAt(k, err)
 			}
 			_isLocalReceived = true
 		case "number":
-			if err := json.Unmarshal([]byte(v), &r.Number); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Number); err != nil {
+				errs.addAt(k, err)
 			}
 			numberReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "id", "_isLocal", "number", "parameters"))
 		}
 	}
 	// check if id (a required property) was received
 	if !idReceived {
-		return errors.New("\"id\" is required but was not present")
+		errs.add(missingField("id"))
 	}
 	// check if _isLocal (a required property) was received
 	if !_isLocalReceived {
-		return errors.New("\"_isLocal\" is required but was not present")
+		errs.add(missingField("_isLocal"))
 	}
 	// check if number (a required property) was received
 	if !numberReceived {
-		return errors.New("\"number\" is required but was not present")
+		errs.add(missingField("number"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Pipeline) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Pipeline) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // PipelineParameter a pipeline parameter.
@@ -1987,6 +2584,9 @@ type PipelineParameter struct {
 	Name          string             `json:"name"`
 	ParameterType string             `json:"parameterType"`
 	Value         *PipelineParameter `json:"value"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *PipelineParameter) MarshalJSON() ([]byte, error) {
@@ -2062,8 +2662,7 @@ func (r *PipelineParameter) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *PipelineParameter) UnmarshalJSON(b []byte) error {
@@ -2076,57 +2675,69 @@ func (r *PipelineParameter) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "defaultValue":
-			if err := json.Unmarshal([]byte(v), &r.DefaultValue); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.DefaultValue); err != nil {
+				errs.addAt(k, err)
 			}
 			defaultValueReceived = true
 		case "enumValues":
-			if err := json.Unmarshal([]byte(v), &r.EnumValues); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.EnumValues); err != nil {
+				errs.addAt(k, err)
 			}
 			enumValuesReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameterType":
-			if err := json.Unmarshal([]byte(v), &r.ParameterType); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ParameterType); err != nil {
+				errs.addAt(k, err)
 			}
 			parameterTypeReceived = true
 		case "value":
-			if err := json.Unmarshal([]byte(v), &r.Value); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Value); err != nil {
+				errs.addAt(k, err)
 			}
 			valueReceived = true
+		default:
+			errs.add(unknownField(k, "defaultValue", "enumValues", "name", "parameterType", "value"))
 		}
 	}
 	// check if defaultValue (a required property) was received
 	if !defaultValueReceived {
-		return errors.New("\"defaultValue\" is required but was not present")
+		errs.add(missingField("defaultValue"))
 	}
 	// check if enumValues (a required property) was received
 	if !enumValuesReceived {
-		return errors.New("\"enumValues\" is required but was not present")
+		errs.add(missingField("enumValues"))
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameterType (a required property) was received
 	if !parameterTypeReceived {
-		return errors.New("\"parameterType\" is required but was not present")
+		errs.add(missingField("parameterType"))
 	}
 	// check if value (a required property) was received
 	if !valueReceived {
-		return errors.New("\"value\" is required but was not present")
+		errs.add(missingField("value"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *PipelineParameter) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *PipelineParameter) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Project pipeline project level information.
@@ -2137,6 +2748,9 @@ type Project struct {
 
 	// The lower-case name of the VCS provider, E.g. “github”, “bitbucket”
 	Vcs string `json:"vcs"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Project) MarshalJSON() ([]byte, error) {
@@ -2184,8 +2798,7 @@ func (r *Project) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Project) UnmarshalJSON(b []byte) error {
@@ -2196,39 +2809,51 @@ func (r *Project) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "git_url":
-			if err := json.Unmarshal([]byte(v), &r.GitUrl); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.GitUrl); err != nil {
+				errs.addAt(k, err)
 			}
 			git_urlReceived = true
 		case "_isLocal":
-			if err := json.Unmarshal([]byte(v), &r.IsLocal); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.IsLocal); err != nil {
+				errs.addAt(k, err)
 			}
 			_isLocalReceived = true
 		case "vcs":
-			if err := json.Unmarshal([]byte(v), &r.Vcs); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Vcs); err != nil {
+				errs.addAt(k, err)
 			}
 			vcsReceived = true
+		default:
+			errs.add(unknownField(k, "git_url", "_isLocal", "vcs"))
 		}
 	}
 	// check if git_url (a required property) was received
 	if !git_urlReceived {
-		return errors.New("\"git_url\" is required but was not present")
+		errs.add(missingField("git_url"))
 	}
 	// check if _isLocal (a required property) was received
 	if !_isLocalReceived {
-		return errors.New("\"_isLocal\" is required but was not present")
+		errs.add(missingField("_isLocal"))
 	}
 	// check if vcs (a required property) was received
 	if !vcsReceived {
-		return errors.New("\"vcs\" is required but was not present")
+		errs.add(missingField("vcs"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Project) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Project) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Restore restores a previously saved cache based on a key..cache needs to have been saved first for this key using save_cache step.
@@ -2237,6 +2862,9 @@ func (r *Project) UnmarshalJSON(b []byte) error {
 type Restore struct {
 	Name       string                  `json:"name"`
 	Parameters *RestoreCacheParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Restore) MarshalJSON() ([]byte, error) {
@@ -2273,8 +2901,7 @@ func (r *Restore) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Restore) UnmarshalJSON(b []byte) error {
@@ -2284,106 +2911,195 @@ func (r *Restore) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Restore) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Restore) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // RestoreCacheParameters command parameters for the restorecache command.
+//
+// Exactly one of Key and Keys is set.
 type RestoreCacheParameters struct {
+	// Single cache key to restore.
+	Key string `json:"key,omitempty"`
+
 	// List of cache keys to lookup for a cache to restore. Only first existing key will be restored.
-	Keys []string `json:"keys"`
+	Keys []string `json:"keys,omitempty"`
 
 	// Title of the step to be shown in the CircleCI UI (default: full command)
 	Name string `json:"name,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *RestoreCacheParameters) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// "Keys" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "keys" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the "key" field
+	if r.Key != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"key\": ")
+		if tmp, err := json.Marshal(r.Key); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"keys\": ")
-	if tmp, err := json.Marshal(r.Keys); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "keys" field
+	if r.Keys != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"keys\": ")
+		if tmp, err := json.Marshal(r.Keys); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *RestoreCacheParameters) UnmarshalJSON(b []byte) error {
+	keyReceived := false
 	keysReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "key":
+			if err := unmarshalValue(v, &r.Key); err != nil {
+				errs.addAt(k, err)
+			}
+			keyReceived = true
 		case "keys":
-			if err := json.Unmarshal([]byte(v), &r.Keys); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Keys); err != nil {
+				errs.addAt(k, err)
 			}
 			keysReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "key", "keys", "name"))
 		}
 	}
-	// check if keys (a required property) was received
-	if !keysReceived {
-		return errors.New("\"keys\" is required but was not present")
+	// check if exactly one of key and keys was received
+	switch {
+	case keyReceived && keysReceived:
+		errs.add(conflictingKeys("key", "keys"))
+	case !keyReceived && !keysReceived:
+		errs.add(&InvalidValueError{Reason: `one of "key", "keys" is required`})
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *RestoreCacheParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *RestoreCacheParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// ReusedExecutor a reference to a reusable executor of the config, written either as its name or as an object holding its
+// name and the arguments passed to it.
+type ReusedExecutor struct {
+	// the arguments passed to the parameters of the executor.
+	Arguments map[string]interface{} `json:"-,omitempty"`
+
+	// the name of the executor.
+	Name string `json:"name"`
+
+	unknownFields
+}
+
+func (r *ReusedExecutor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&ExecutorReferenceSchema{AdditionalProperties: r.Arguments, Name: r.Name})
+}
+
+func (r *ReusedExecutor) UnmarshalJSON(b []byte) error {
+	var ref ExecutorReferenceSchema
+	err := unmarshalValue(b, &ref)
+	r.Arguments, r.Name = ref.AdditionalProperties, ref.Name
+	return r.keepUnknownFields(err)
+}
+
+func (r *ReusedExecutor) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *ReusedExecutor) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Run the run command step is used for invoking all command-line programs.
 type Run struct {
 	Name       string         `json:"name"`
 	Parameters *RunParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Run) MarshalJSON() ([]byte, error) {
@@ -2420,8 +3136,7 @@ func (r *Run) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Run) UnmarshalJSON(b []byte) error {
@@ -2431,42 +3146,60 @@ func (r *Run) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Run) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Run) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // RunParameters command parameters for the run command.
 type RunParameters struct {
-	// Whether or not this step should run in the background (default: false)
-	Background bool `json:"background,omitempty"`
+	// Delay between automatic reruns of the step, as a duration such as “30s” (default: 0s)
+	AutoRerunDelay string `json:"auto_rerun_delay,omitempty"`
+
+	// Whether or not this step should run in the background (default: false): a bool, or a parameter expression string.
+	Background interface{} `json:"background,omitempty"`
 
 	// Command to run via the shell
 	Command string `json:"command"`
 
 	// Additional environmental variables, locally scoped to command
-	Environment map[string]string `json:"environment,omitempty"`
+	Environment Environment `json:"environment,omitempty"`
+
+	// Number of times the step is rerun automatically if it fails, from 1 to 5: an int, or a parameter expression string.
+	MaxAutoReruns interface{} `json:"max_auto_reruns,omitempty"`
 
 	// Title of the step to be shown in the CircleCI UI (default: full command)
 	Name string `json:"name,omitempty"`
@@ -2482,23 +3215,41 @@ type RunParameters struct {
 
 	// In which directory to run this step. Will be interpreted relative to the working_directory of the job). (default: .)
 	WorkingDirectory string `json:"working_directory,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *RunParameters) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal the "background" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the "auto_rerun_delay" field
+	if r.AutoRerunDelay != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"auto_rerun_delay\": ")
+		if tmp, err := json.Marshal(r.AutoRerunDelay); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"background\": ")
-	if tmp, err := json.Marshal(r.Background); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "background" field
+	if r.Background != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"background\": ")
+		if tmp, err := json.Marshal(r.Background); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Command" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "command" field
@@ -2513,126 +3264,180 @@ func (r *RunParameters) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "environment" field
-	if comma {
-		buf.WriteString(",")
+	if len(r.Environment) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"environment\": ")
+		if tmp, err := json.Marshal(r.Environment); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"environment\": ")
-	if tmp, err := json.Marshal(r.Environment); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "max_auto_reruns" field
+	if r.MaxAutoReruns != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"max_auto_reruns\": ")
+		if tmp, err := json.Marshal(r.MaxAutoReruns); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
 	// Marshal the "no_output_timeout" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"no_output_timeout\": ")
-	if tmp, err := json.Marshal(r.NoOutputTimeout); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.NoOutputTimeout != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"no_output_timeout\": ")
+		if tmp, err := json.Marshal(r.NoOutputTimeout); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "shell" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"shell\": ")
-	if tmp, err := json.Marshal(r.Shell); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Shell != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"shell\": ")
+		if tmp, err := json.Marshal(r.Shell); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "when" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"when\": ")
-	if tmp, err := json.Marshal(r.When); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.When != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"when\": ")
+		if tmp, err := json.Marshal(r.When); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "working_directory" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"working_directory\": ")
-	if tmp, err := json.Marshal(r.WorkingDirectory); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.WorkingDirectory != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"working_directory\": ")
+		if tmp, err := json.Marshal(r.WorkingDirectory); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *RunParameters) UnmarshalJSON(b []byte) error {
+	// the short form is the command to run
+	var command string
+	if err := json.Unmarshal(b, &command); err == nil {
+		r.Command = command
+		return nil
+	}
 	commandReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
-		return err
+		var v interface{}
+		if json.Unmarshal(b, &v) != nil {
+			return err
+		}
+		return &TypeMismatchError{Expected: "string or object", Actual: jsonValueType(v)}
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "auto_rerun_delay":
+			if err := unmarshalValue(v, &r.AutoRerunDelay); err != nil {
+				errs.addAt(k, err)
+			}
 		case "background":
-			if err := json.Unmarshal([]byte(v), &r.Background); err != nil {
-				return err
+			if err := unmarshalBoolOrExpression(v, &r.Background); err != nil {
+				errs.addAt(k, err)
 			}
 		case "command":
-			if err := json.Unmarshal([]byte(v), &r.Command); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Command); err != nil {
+				errs.addAt(k, err)
 			}
 			commandReceived = true
 		case "environment":
-			if err := json.Unmarshal([]byte(v), &r.Environment); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Environment); err != nil {
+				errs.addAt(k, err)
+			}
+		case "max_auto_reruns":
+			if err := unmarshalIntOrExpression(v, &r.MaxAutoReruns, 1, 5); err != nil {
+				errs.addAt(k, err)
 			}
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "no_output_timeout":
-			if err := json.Unmarshal([]byte(v), &r.NoOutputTimeout); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.NoOutputTimeout); err != nil {
+				errs.addAt(k, err)
 			}
 		case "shell":
-			if err := json.Unmarshal([]byte(v), &r.Shell); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Shell); err != nil {
+				errs.addAt(k, err)
 			}
 		case "when":
-			if err := json.Unmarshal([]byte(v), &r.When); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.When); err != nil {
+				errs.addAt(k, err)
 			}
 		case "working_directory":
-			if err := json.Unmarshal([]byte(v), &r.WorkingDirectory); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.WorkingDirectory); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "auto_rerun_delay", "background", "command", "environment", "max_auto_reruns", "name", "no_output_timeout", "shell", "when", "working_directory"))
 		}
 	}
 	// check if command (a required property) was received
 	if !commandReceived {
-		return errors.New("\"command\" is required but was not present")
+		errs.add(missingField("command"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *RunParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *RunParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Save generates and stores a cache of a file or directory of files such as dependencies or source code in our object storage.
@@ -2641,6 +3446,9 @@ func (r *RunParameters) UnmarshalJSON(b []byte) error {
 type Save struct {
 	Name       string               `json:"name"`
 	Parameters *SaveCacheParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Save) MarshalJSON() ([]byte, error) {
@@ -2677,8 +3485,7 @@ func (r *Save) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Save) UnmarshalJSON(b []byte) error {
@@ -2688,30 +3495,42 @@ func (r *Save) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Save) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Save) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // SaveCacheParameters command parameters for the savecache command.
@@ -2727,6 +3546,9 @@ type SaveCacheParameters struct {
 
 	// Specify when to enable or disable the step.
 	When string `json:"when,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *SaveCacheParameters) MarshalJSON() ([]byte, error) {
@@ -2747,16 +3569,18 @@ func (r *SaveCacheParameters) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Paths" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "paths" field
@@ -2771,20 +3595,21 @@ func (r *SaveCacheParameters) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "when" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"when\": ")
-	if tmp, err := json.Marshal(r.When); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.When != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"when\": ")
+		if tmp, err := json.Marshal(r.When); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *SaveCacheParameters) UnmarshalJSON(b []byte) error {
@@ -2794,44 +3619,153 @@ func (r *SaveCacheParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "key":
-			if err := json.Unmarshal([]byte(v), &r.Key); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Key); err != nil {
+				errs.addAt(k, err)
 			}
 			keyReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "paths":
-			if err := json.Unmarshal([]byte(v), &r.Paths); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Paths); err != nil {
+				errs.addAt(k, err)
 			}
 			pathsReceived = true
 		case "when":
-			if err := json.Unmarshal([]byte(v), &r.When); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.When); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "key", "name", "paths", "when"))
 		}
 	}
 	// check if key (a required property) was received
 	if !keyReceived {
-		return errors.New("\"key\" is required but was not present")
+		errs.add(missingField("key"))
 	}
 	// check if paths (a required property) was received
 	if !pathsReceived {
-		return errors.New("\"paths\" is required but was not present")
+		errs.add(missingField("paths"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *SaveCacheParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *SaveCacheParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// Schedule runs a workflow at the times given by a cron expression, on the branches selected by its filters.
+type Schedule struct {
+	// The times the workflow is run at, in POSIX crontab syntax, in UTC.
+	Cron string `json:"cron"`
+
+	// The branches the workflow is run on.
+	Filters *WorkflowFilterSchema `json:"filters"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *Schedule) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Cron" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "cron" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"cron\": ")
+	if tmp, err := json.Marshal(r.Cron); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// "Filters" field is required
+	if r.Filters == nil {
+		return nil, errors.New("filters is a required field")
+	}
+	// Marshal the "filters" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"filters\": ")
+	if tmp, err := json.Marshal(r.Filters); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *Schedule) UnmarshalJSON(b []byte) error {
+	cronReceived := false
+	filtersReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "cron":
+			if err := unmarshalValue(v, &r.Cron); err != nil {
+				errs.addAt(k, err)
+			}
+			cronReceived = true
+		case "filters":
+			if err := unmarshalValue(v, &r.Filters); err != nil {
+				errs.addAt(k, err)
+			}
+			filtersReceived = true
+		default:
+			errs.add(unknownField(k, "cron", "filters"))
+		}
+	}
+	// check if cron (a required property) was received
+	if !cronReceived {
+		errs.add(missingField("cron"))
+	}
+	// check if filters (a required property) was received
+	if !filtersReceived {
+		errs.add(missingField("filters"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Schedule) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Schedule) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // SetupRemoteDocker creates a remote docker environment configured to execute docker commands.
 type SetupRemoteDocker struct {
 	Name       string                       `json:"name"`
 	Parameters *SetupRemoteDockerParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *SetupRemoteDocker) MarshalJSON() ([]byte, error) {
@@ -2868,8 +3802,7 @@ func (r *SetupRemoteDocker) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *SetupRemoteDocker) UnmarshalJSON(b []byte) error {
@@ -2879,107 +3812,151 @@ func (r *SetupRemoteDocker) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *SetupRemoteDocker) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *SetupRemoteDocker) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // SetupRemoteDockerParameters command parameters for the setupremotedocker command.
 type SetupRemoteDockerParameters struct {
+	// Whether to enable docker layer caching for the remote docker environment (default: false): a bool, or a parameter expression string.
+	DockerLayerCaching interface{} `json:"docker_layer_caching,omitempty"`
+
 	// Title of the step to be shown in the CircleCI UI (default: full command)
 	Name string `json:"name,omitempty"`
 
-	// SetupRemoteDocker directory.
-	// Will be interpreted relative to the working_directory of the job.
-	Version string `json:"version"`
+	// Version of docker to use in the remote docker environment (default: the default version of CircleCI)
+	Version string `json:"version,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *SetupRemoteDockerParameters) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the "docker_layer_caching" field
+	if r.DockerLayerCaching != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"docker_layer_caching\": ")
+		if tmp, err := json.Marshal(r.DockerLayerCaching); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "name" field
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// "Version" field is required
-	// only required object types supported for marshal checking (for now)
 	// Marshal the "version" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"version\": ")
-	if tmp, err := json.Marshal(r.Version); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Version != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"version\": ")
+		if tmp, err := json.Marshal(r.Version); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *SetupRemoteDockerParameters) UnmarshalJSON(b []byte) error {
-	versionReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "docker_layer_caching":
+			if err := unmarshalBoolOrExpression(v, &r.DockerLayerCaching); err != nil {
+				errs.addAt(k, err)
+			}
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "version":
-			if err := json.Unmarshal([]byte(v), &r.Version); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Version); err != nil {
+				errs.addAt(k, err)
 			}
-			versionReceived = true
+		default:
+			errs.add(unknownField(k, "docker_layer_caching", "name", "version"))
 		}
 	}
-	// check if version (a required property) was received
-	if !versionReceived {
-		return errors.New("\"version\" is required but was not present")
-	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *SetupRemoteDockerParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *SetupRemoteDockerParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // StoreArtifacts a special step used to check out source code to the configured path (defaults to the working_directory).
 type StoreArtifacts struct {
 	Name       string                    `json:"name"`
 	Parameters *StoreArtifactsParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *StoreArtifacts) MarshalJSON() ([]byte, error) {
@@ -3016,8 +3993,7 @@ func (r *StoreArtifacts) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *StoreArtifacts) UnmarshalJSON(b []byte) error {
@@ -3027,30 +4003,42 @@ func (r *StoreArtifacts) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *StoreArtifacts) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StoreArtifacts) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // StoreArtifactsParameters command parameters for the storeartifacts command.
@@ -3063,6 +4051,9 @@ type StoreArtifactsParameters struct {
 
 	// Directory in the primary container to save as job artifacts
 	Path string `json:"path"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *StoreArtifactsParameters) MarshalJSON() ([]byte, error) {
@@ -3070,27 +4061,31 @@ func (r *StoreArtifactsParameters) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "destination" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"destination\": ")
-	if tmp, err := json.Marshal(r.Destination); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Destination != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"destination\": ")
+		if tmp, err := json.Marshal(r.Destination); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Path" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "path" field
@@ -3106,8 +4101,7 @@ func (r *StoreArtifactsParameters) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *StoreArtifactsParameters) UnmarshalJSON(b []byte) error {
@@ -3116,29 +4110,41 @@ func (r *StoreArtifactsParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "destination":
-			if err := json.Unmarshal([]byte(v), &r.Destination); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Destination); err != nil {
+				errs.addAt(k, err)
 			}
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "path":
-			if err := json.Unmarshal([]byte(v), &r.Path); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Path); err != nil {
+				errs.addAt(k, err)
 			}
 			pathReceived = true
+		default:
+			errs.add(unknownField(k, "destination", "name", "path"))
 		}
 	}
 	// check if path (a required property) was received
 	if !pathReceived {
-		return errors.New("\"path\" is required but was not present")
+		errs.add(missingField("path"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *StoreArtifactsParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StoreArtifactsParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // StoreTestResults special step used to upload and store test results for a build.
@@ -3149,6 +4155,9 @@ func (r *StoreArtifactsParameters) UnmarshalJSON(b []byte) error {
 type StoreTestResults struct {
 	Name       string                      `json:"name"`
 	Parameters *StoreTestResultsParameters `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *StoreTestResults) MarshalJSON() ([]byte, error) {
@@ -3185,8 +4194,7 @@ func (r *StoreTestResults) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *StoreTestResults) UnmarshalJSON(b []byte) error {
@@ -3196,30 +4204,42 @@ func (r *StoreTestResults) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "name", "parameters"))
 		}
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *StoreTestResults) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StoreTestResults) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // StoreTestResultsParameters command parameters for the storetestresults command.
@@ -3229,6 +4249,9 @@ type StoreTestResultsParameters struct {
 
 	// Path (absolute, or relative to your working_directory) to directory containing subdirectories of JUnit XML or Cucumber JSON test metadata files
 	Path string `json:"path"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *StoreTestResultsParameters) MarshalJSON() ([]byte, error) {
@@ -3236,16 +4259,18 @@ func (r *StoreTestResultsParameters) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Path" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "path" field
@@ -3261,8 +4286,7 @@ func (r *StoreTestResultsParameters) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *StoreTestResultsParameters) UnmarshalJSON(b []byte) error {
@@ -3271,34 +4295,182 @@ func (r *StoreTestResultsParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "path":
-			if err := json.Unmarshal([]byte(v), &r.Path); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Path); err != nil {
+				errs.addAt(k, err)
 			}
 			pathReceived = true
+		default:
+			errs.add(unknownField(k, "name", "path"))
 		}
 	}
 	// check if path (a required property) was received
 	if !pathReceived {
-		return errors.New("\"path\" is required but was not present")
+		errs.add(missingField("path"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *StoreTestResultsParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StoreTestResultsParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// StringOrList a list of strings, which may also be written as a single string, as in `only: main`.
+//
+// A single string is decoded as a list of one element; it is always encoded as a list.
+type StringOrList []string
+
+func (r *StringOrList) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case string:
+		*r = StringOrList{v}
+		return nil
+	case []interface{}:
+		var list []string
+		err := unmarshalValue(b, &list)
+		*r = list
+		return err
+	}
+	return &TypeMismatchError{Expected: "string or array", Actual: jsonValueType(v)}
+}
+
+func (r StringOrList) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StringOrList) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Tags a map defining rules for execution on specific tags.
 type Tags struct {
 	// Either a single tag specifier, or a list of tag specifiers
-	Ignore []string `json:"ignore,omitempty"`
+	Ignore StringOrList `json:"ignore,omitempty"`
 
 	// Either a single tag specifier, or a list of tag specifiers
-	Only []string `json:"only,omitempty"`
+	Only StringOrList `json:"only,omitempty"`
+
+	unknownFields
+}
+
+func (r *Tags) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "ignore":
+			if err := unmarshalValue(v, &r.Ignore); err != nil {
+				errs.addAt(k, err)
+			}
+		case "only":
+			if err := unmarshalValue(v, &r.Only); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "ignore", "only"))
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Tags) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Tags) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// Trigger an event that runs a workflow, besides a push to the repository.
+type Trigger struct {
+	// Runs the workflow on a schedule.
+	Schedule *Schedule `json:"schedule"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *Trigger) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Schedule" field is required
+	if r.Schedule == nil {
+		return nil, errors.New("schedule is a required field")
+	}
+	// Marshal the "schedule" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"schedule\": ")
+	if tmp, err := json.Marshal(r.Schedule); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *Trigger) UnmarshalJSON(b []byte) error {
+	scheduleReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "schedule":
+			if err := unmarshalValue(v, &r.Schedule); err != nil {
+				errs.addAt(k, err)
+			}
+			scheduleReceived = true
+		default:
+			errs.add(unknownField(k, "schedule"))
+		}
+	}
+	// check if schedule (a required property) was received
+	if !scheduleReceived {
+		errs.add(missingField("schedule"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Trigger) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Trigger) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // WindowsExecutor a Windows virtual machine (CircleCI Cloud).
@@ -3308,6 +4480,9 @@ type WindowsExecutor struct {
 	// Select one of the available Windows VM Images provided by CircleCI
 	Image         string `json:"image"`
 	ResourceClass string `json:"resourceClass"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *WindowsExecutor) MarshalJSON() ([]byte, error) {
@@ -3315,16 +4490,18 @@ func (r *WindowsExecutor) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "description" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"description\": ")
-	if tmp, err := json.Marshal(r.Description); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Image" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "image" field
@@ -3353,8 +4530,7 @@ func (r *WindowsExecutor) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *WindowsExecutor) UnmarshalJSON(b []byte) error {
@@ -3364,34 +4540,46 @@ func (r *WindowsExecutor) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "description":
-			if err := json.Unmarshal([]byte(v), &r.Description); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
 			}
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
 			imageReceived = true
 		case "resourceClass":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
 			}
 			resourceClassReceived = true
+		default:
+			errs.add(unknownField(k, "description", "image", "resourceClass"))
 		}
 	}
 	// check if image (a required property) was received
 	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
+		errs.add(missingField("image"))
 	}
 	// check if resourceClass (a required property) was received
 	if !resourceClassReceived {
-		return errors.New("\"resourceClass\" is required but was not present")
+		errs.add(missingField("resourceClass"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WindowsExecutor) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WindowsExecutor) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // Workflow a workflow is a set of rules for defining a collection of jobs and their run order.
@@ -3401,6 +4589,9 @@ type Workflow struct {
 
 	// The name of the Workflow.
 	Name string `json:"name"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *Workflow) MarshalJSON() ([]byte, error) {
@@ -3435,8 +4626,7 @@ func (r *Workflow) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *Workflow) UnmarshalJSON(b []byte) error {
@@ -3446,30 +4636,42 @@ func (r *Workflow) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "jobs":
-			if err := json.Unmarshal([]byte(v), &r.Jobs); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Jobs); err != nil {
+				errs.addAt(k, err)
 			}
 			jobsReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 			nameReceived = true
+		default:
+			errs.add(unknownField(k, "jobs", "name"))
 		}
 	}
 	// check if jobs (a required property) was received
 	if !jobsReceived {
-		return errors.New("\"jobs\" is required but was not present")
+		errs.add(missingField("jobs"))
 	}
 	// check if name (a required property) was received
 	if !nameReceived {
-		return errors.New("\"name\" is required but was not present")
+		errs.add(missingField("name"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *Workflow) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Workflow) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // WorkflowJob assign parameters and filters to a Job within a Workflow.
@@ -3480,6 +4682,9 @@ func (r *Workflow) UnmarshalJSON(b []byte) error {
 type WorkflowJob struct {
 	Job        *Job                   `json:"job"`
 	Parameters *WorkflowJobParameters `json:"parameters,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *WorkflowJob) MarshalJSON() ([]byte, error) {
@@ -3502,20 +4707,21 @@ func (r *WorkflowJob) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "parameters" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"parameters\": ")
-	if tmp, err := json.Marshal(r.Parameters); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *WorkflowJob) UnmarshalJSON(b []byte) error {
@@ -3524,38 +4730,50 @@ func (r *WorkflowJob) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "job":
-			if err := json.Unmarshal([]byte(v), &r.Job); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Job); err != nil {
+				errs.addAt(k, err)
 			}
 			jobReceived = true
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "job", "parameters"))
 		}
 	}
 	// check if job (a required property) was received
 	if !jobReceived {
-		return errors.New("\"job\" is required but was not present")
+		errs.add(missingField("job"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WorkflowJob) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WorkflowJob) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // WorkflowJobParameters
 type WorkflowJobParameters struct {
 	AdditionalProperties map[string]interface{} `json:"-,omitempty"`
 
-	Context []string `json:"context,omitempty"`
+	Context StringOrList `json:"context,omitempty"`
 
 	// {@link https://circleci.com/docs/2.0/configuration-reference/#filters} Filter workflow job's execution by branch or git tag.
 	Filters *WorkflowFilterSchema `json:"filters,omitempty"`
 
 	// An "approval" type job is a special job which pauses the workflow. This "job" is not defined outside of the workflow, you may enter any potential name for the job name. As long as the parameter of "type" is present and equal to "approval" this job will act as a placeholder that awaits user input to continue.
-	JobType string `json:"jobType,omitempty"`
+	JobType string `json:"type,omitempty"`
 
 	// {@link https://circleci.com/docs/2.0/configuration-reference/#matrix-requires-version-21} The matrix stanza allows you to run a parameterized job multiple times with different arguments.
 	Matrix *WorkflowMatrixSchema `json:"matrix,omitempty"`
@@ -3563,7 +4781,10 @@ type WorkflowJobParameters struct {
 	Name string `json:"name,omitempty"`
 
 	// A list of jobs that must succeed for the job to start. Note: When jobs in the current workflow that are listed as dependencies are not executed (due to a filter function for example), their requirement as a dependency for other jobs will be ignored by the requires option. However, if all dependencies of a job are filtered, then that job will not be executed either.
-	Requires []string `json:"requires,omitempty"`
+	Requires StringOrList `json:"requires,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *WorkflowJobParameters) MarshalJSON() ([]byte, error) {
@@ -3571,77 +4792,100 @@ func (r *WorkflowJobParameters) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "context" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"context\": ")
-	if tmp, err := json.Marshal(r.Context); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Context) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"context\": ")
+		if tmp, err := json.Marshal(r.Context); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "filters" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"filters\": ")
-	if tmp, err := json.Marshal(r.Filters); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
-	}
-	comma = true
-	// Marshal the "jobType" field
-	if comma {
-		buf.WriteString(",")
+	if r.Filters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"filters\": ")
+		if tmp, err := json.Marshal(r.Filters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"jobType\": ")
-	if tmp, err := json.Marshal(r.JobType); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "type" field
+	if r.JobType != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"type\": ")
+		if tmp, err := json.Marshal(r.JobType); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "matrix" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"matrix\": ")
-	if tmp, err := json.Marshal(r.Matrix); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Matrix != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"matrix\": ")
+		if tmp, err := json.Marshal(r.Matrix); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "requires" field
-	if comma {
-		buf.WriteString(",")
+	if len(r.Requires) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"requires\": ")
+		if tmp, err := json.Marshal(r.Requires); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"requires\": ")
-	if tmp, err := json.Marshal(r.Requires); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal any additional Properties, sorted by key
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
 	}
-	comma = true
-	// Marshal any additional Properties
-	for k, v := range r.AdditionalProperties {
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.AdditionalProperties[k]
 		if comma {
 			buf.WriteString(",")
 		}
-		buf.WriteString(fmt.Sprintf("\"%s\":", k))
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
 		if tmp, err := json.Marshal(v); err != nil {
 			return nil, err
 		} else {
@@ -3651,8 +4895,7 @@ func (r *WorkflowJobParameters) MarshalJSON() ([]byte, error) {
 	}
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *WorkflowJobParameters) UnmarshalJSON(b []byte) error {
@@ -3660,38 +4903,40 @@ func (r *WorkflowJobParameters) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "context":
-			if err := json.Unmarshal([]byte(v), &r.Context); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Context); err != nil {
+				errs.addAt(k, err)
 			}
 		case "filters":
-			if err := json.Unmarshal([]byte(v), &r.Filters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Filters); err != nil {
+				errs.addAt(k, err)
 			}
-		case "jobType":
-			if err := json.Unmarshal([]byte(v), &r.JobType); err != nil {
-				return err
+		case "type", "jobType":
+			if err := unmarshalValue(v, &r.JobType); err != nil {
+				errs.addAt(k, err)
 			}
 		case "matrix":
-			if err := json.Unmarshal([]byte(v), &r.Matrix); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Matrix); err != nil {
+				errs.addAt(k, err)
 			}
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "requires":
-			if err := json.Unmarshal([]byte(v), &r.Requires); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Requires); err != nil {
+				errs.addAt(k, err)
 			}
 		default:
 			// an additional "interface{}" value
 			var additionalValue interface{}
-			if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-				return err // invalid additionalProperty
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
 			if r.AdditionalProperties == nil {
 				r.AdditionalProperties = make(map[string]interface{})
@@ -3699,5 +4944,13 @@ func (r *WorkflowJobParameters) UnmarshalJSON(b []byte) error {
 			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WorkflowJobParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WorkflowJobParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
@@ -0,0 +1,91 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStringOrList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want StringOrList
+		err  string
+	}{
+		{in: `"main"`, want: StringOrList{"main"}},
+		{in: `["main", "/release-.*/"]`, want: StringOrList{"main", "/release-.*/"}},
+		{in: `[]`, want: StringOrList{}},
+		{in: `null`, want: nil},
+		{in: `1`, err: "expected string or array but got number"},
+		{in: `[1]`, err: "/0: expected string but got number"},
+	}
+	for _, tt := range tests {
+		var got StringOrList
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("json.Unmarshal(%s) error = %v, want %s", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("json.Unmarshal(%s) error = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("json.Unmarshal(%s) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateStringOrList(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "single strings",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a, entrypoint: /bin/sh, command: make}]
+    steps: [checkout]
+  test:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build:
+          context: org-global
+          filters:
+            branches: {only: main, ignore: /wip-.*/}
+            tags: {only: /^v.*/}
+      - test:
+          requires: build
+`,
+		},
+		{
+			name: "neither string nor list",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build:
+          filters:
+            branches: {only: {name: main}}
+`,
+			want: []string{
+				`12:24: error: /workflows/main/jobs/0/build/filters/branches/only: expected string or array but got object`,
+			},
+		},
+	})
+}
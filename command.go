@@ -0,0 +1,94 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"sort"
+)
+
+// builtinStepNames returns the sorted names of the steps built into CircleCI. Any other step invokes a reusable command.
+func builtinStepNames() []string {
+	names := make([]string, 0, len(builtinStepTypes))
+	for name := range builtinStepTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCommands reports steps of jobs and commands that invoke a reusable command that is not defined, or pass it invalid arguments.
+//
+// Commands of orbs, such as "node/install-packages", and expansions of steps parameters are not checked.
+func checkCommands(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Jobs != nil {
+		for _, name := range sortedJobNames(cfg.Jobs) {
+			if job := cfg.Jobs.AdditionalProperties[name]; job != nil {
+				checkCommandSteps(cfg, report, jsonPointer("jobs", name, "steps"), job.Steps)
+			}
+		}
+	}
+	if cfg.Commands != nil {
+		for _, name := range sortedCommandNames(cfg.Commands) {
+			if cmd := cfg.Commands.AdditionalProperties[name]; cmd != nil {
+				checkCommandSteps(cfg, report, jsonPointer("commands", name, "steps"), cmd.Steps)
+			}
+		}
+	}
+}
+
+// checkCommandSteps checks the steps at the JSON pointer ptr. See checkCommands.
+func checkCommandSteps(cfg *CircleCIConfigSchema, report *Report, ptr string, steps Steps) {
+	for i, step := range steps {
+		if step == nil {
+			continue
+		}
+		stepPtr := stepPointer(ptr, i, step)
+		switch s := step.(type) {
+		case *WhenCommandSchema:
+			if s.When != nil {
+				checkCommandSteps(cfg, report, stepPtr+jsonPointer("steps"), s.When.Steps)
+			}
+		case *UnlessCommandSchema:
+			if s.Unless != nil {
+				checkCommandSteps(cfg, report, stepPtr+jsonPointer("steps"), s.Unless.Steps)
+			}
+		case *StepListStep:
+			checkCommandSteps(cfg, report, stepPtr, s.Steps)
+		case *CustomCommandStep:
+			if isParameterExpression(s.Command) {
+				continue
+			}
+			var cmd *CommandSchemaItem
+			if cfg.Commands != nil {
+				cmd = cfg.Commands.AdditionalProperties[s.Command]
+			}
+			if cmd == nil {
+				report.add(SeverityError, &InvalidValueError{
+					Path:       stepPtr,
+					Value:      s.Command,
+					Reason:     "no such command",
+					Suggestion: suggest(s.Command, append(commandNames(cfg), builtinStepNames()...)),
+				})
+				continue
+			}
+			checkArguments(report, stepPtr, cmd.Parameters, s.Arguments, nil)
+		}
+	}
+}
+
+func commandNames(cfg *CircleCIConfigSchema) []string {
+	if cfg.Commands == nil {
+		return nil
+	}
+	return sortedCommandNames(cfg.Commands)
+}
+
+func sortedCommandNames(s *CommandSchema) []string {
+	names := make([]string, 0, len(s.AdditionalProperties))
+	for name := range s.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import "testing"
+
+func TestValidateCommands(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "valid",
+			config: `
+version: 2.1
+commands:
+  greet:
+    parameters:
+      to: {type: string, default: world}
+    steps:
+      - run: echo hello << parameters.to >>
+  setup:
+    steps: [checkout, greet]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - setup
+      - greet: {to: you}
+workflows:
+  main:
+    jobs: [build]
+`,
+		},
+		{
+			name: "invalid invocations",
+			config: `
+version: 2.1
+commands:
+  greet:
+    parameters:
+      to: {type: string}
+    steps:
+      - run: echo hello << parameters.to >>
+      - nope
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - greet
+      - greet: {to: you, from: me}
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`9:9: error: /commands/greet/steps/1: invalid value "nope": no such command`,
+				`14:9: error: /jobs/build/steps/0: "to" is required but was not present`,
+				`15:26: error: /jobs/build/steps/1/greet: unknown field "from"`,
+			},
+		},
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Position a location in a YAML source. Line and Column are 1-based; the zero value means the position is unknown.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// IsValid reports whether the position is known.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Document a decoded config together with the source position of each of its nodes.
+type Document struct {
+	Config *CircleCIConfigSchema
+
+	// source the JSON encoding of the YAML source, as validated by ValidateSchema.
+	source []byte
+
+	// positions the position of every node of the source, keyed by JSON pointer.
+	positions map[string]Position
+}
+
+// Position returns the source position of the node at the JSON pointer ptr.
+//
+// If the node does not exist in the source, for example because it refers to a missing field, the position of its closest existing ancestor is returned.
+// ok is false if no position is known.
+func (d *Document) Position(ptr string) (pos Position, ok bool) {
+	for {
+		if pos, ok := d.positions[ptr]; ok {
+			return pos, true
+		}
+		i := strings.LastIndex(ptr, "/")
+		if i < 0 {
+			return Position{}, false
+		}
+		ptr = ptr[:i]
+	}
+}
+
+// errEmptyDocument the error returned when decoding an input that holds no YAML document.
+var errEmptyDocument = errors.New("config is empty")
+
+// Decoder reads and decodes a CircleCI config from an input stream.
+type Decoder struct {
+	r io.Reader
+
+	disallowUnknownFields bool
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// DisallowUnknownFields causes the Decoder to report keys that are not part of a closed object, such as a misspelled "comand" under "run", as *UnknownFieldError.
+// By default they are silently ignored.
+func (d *Decoder) DisallowUnknownFields() {
+	d.disallowUnknownFields = true
+}
+
+// Decode reads the YAML config from its input and decodes it into a Document.
+//
+// If the input is a YAML document but does not match the schema, Decode returns both the Document, populated as far as possible, and the decoding error.
+func (d *Decoder) Decode() (*Document, error) {
+	b, err := io.ReadAll(d.r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocument(b, d.disallowUnknownFields)
+}
+
+// decodeDocument decodes the YAML config b into a Document. See Decoder.Decode.
+func decodeDocument(b []byte, disallowUnknownFields bool) (*Document, error) {
+	js, positions, err := yamlToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	if string(js) == "null" {
+		return nil, errEmptyDocument
+	}
+
+	doc := &Document{
+		Config:    new(CircleCIConfigSchema),
+		source:    js,
+		positions: positions,
+	}
+	err = json.Unmarshal(js, doc.Config)
+	if unknown := doc.Config.takeUnknownFields(); disallowUnknownFields && len(unknown) > 0 {
+		var errs Errors
+		if err != nil {
+			errs.add(err)
+		}
+		err = append(errs, unknown...).err()
+	}
+	if err != nil {
+		return doc, locate("", err)
+	}
+	return doc, nil
+}
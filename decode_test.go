@@ -0,0 +1,266 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecoderPositions(t *testing.T) {
+	t.Parallel()
+
+	config := `version: 2.1
+defaults: &defaults
+  docker:
+    - image: cimg/go:1.17
+jobs:
+  build:
+    <<: *defaults
+    steps:
+      - checkout
+      - run: go test ./...
+workflows:
+  main:
+    jobs: [build]
+`
+	doc, err := NewDecoder(strings.NewReader(config)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if _, ok := doc.Config.Jobs.AdditionalProperties["build"]; !ok {
+		t.Fatal("job build was not decoded")
+	}
+
+	tests := []struct {
+		ptr  string
+		want Position
+		ok   bool
+	}{
+		{ptr: "", want: Position{Line: 1, Column: 1}, ok: true},
+		{ptr: "/version", want: Position{Line: 1, Column: 1}, ok: true},
+		{ptr: "/jobs/build", want: Position{Line: 6, Column: 3}, ok: true},
+		{ptr: "/jobs/build/docker/0/image", want: Position{Line: 4, Column: 7}, ok: true},
+		{ptr: "/jobs/build/steps/1/run", want: Position{Line: 10, Column: 9}, ok: true},
+		{ptr: "/workflows/main/jobs/0", want: Position{Line: 13, Column: 12}, ok: true},
+		// a missing node is positioned at its closest ancestor
+		{ptr: "/jobs/build/resource_class", want: Position{Line: 6, Column: 3}, ok: true},
+	}
+	for _, tt := range tests {
+		got, ok := doc.Position(tt.ptr)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Position(%q) = %v, %t, want %v, %t", tt.ptr, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if _, err := NewDecoder(strings.NewReader("")).Decode(); err != errEmptyDocument {
+		t.Errorf("Decode() of an empty input error = %v, want %v", err, errEmptyDocument)
+	}
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	var cfg CircleCIConfigSchema
+	if err := yaml.Unmarshal([]byte(validConfig), &cfg); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	if cfg.Version != 2.1 {
+		t.Errorf("Version = %v, want 2.1", cfg.Version)
+	}
+	if _, ok := cfg.Workflows.AdditionalProperties["main"]; !ok {
+		t.Errorf("workflow main was not decoded")
+	}
+}
+
+func TestUnmarshalIgnoresUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	var executor DockerExecutorSchema
+	if err := json.Unmarshal([]byte(`{"docker":[{"image":"a","foo":1}]}`), &executor); err != nil {
+		t.Errorf("json.Unmarshal() error = %v", err)
+	}
+	if len(executor.Docker) != 1 || executor.Docker[0].Image != "a" {
+		t.Errorf("json.Unmarshal() decoded %+v", executor.Docker)
+	}
+
+	var steps Steps
+	if err := json.Unmarshal([]byte(`[{"run":{"command":"make","comand":"make"}}]`), &steps); err != nil {
+		t.Errorf("json.Unmarshal() error = %v", err)
+	}
+
+	var cfg CircleCIConfigSchema
+	config := `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a, foo: 1}]
+    steps:
+      - run: {command: make, comand: make}
+workflows:
+  main:
+    jobs: [build]
+`
+	if err := yaml.Unmarshal([]byte(config), &cfg); err != nil {
+		t.Errorf("yaml.Unmarshal() error = %v", err)
+	}
+}
+
+func TestYAMLUnmarshalShortForms(t *testing.T) {
+	t.Parallel()
+
+	var list StringOrList
+	if err := yaml.Unmarshal([]byte(`main`), &list); err != nil || len(list) != 1 || list[0] != "main" {
+		t.Errorf("yaml.Unmarshal() into StringOrList = %q, %v, want [main]", list, err)
+	}
+
+	var branches Branches
+	if err := yaml.Unmarshal([]byte(`only: main`), &branches); err != nil || len(branches.Only) != 1 || branches.Only[0] != "main" {
+		t.Errorf("yaml.Unmarshal() into Branches = %+v, %v, want only main", branches, err)
+	}
+
+	var tags Tags
+	if err := yaml.Unmarshal([]byte(`ignore: /.*/`), &tags); err != nil || len(tags.Ignore) != 1 || tags.Ignore[0] != "/.*/" {
+		t.Errorf("yaml.Unmarshal() into Tags = %+v, %v, want ignore /.*/", tags, err)
+	}
+
+	var run RunParameters
+	if err := yaml.Unmarshal([]byte(`make test`), &run); err != nil || run.Command != "make test" {
+		t.Errorf("yaml.Unmarshal() into RunParameters = %+v, %v, want the command make test", run, err)
+	}
+
+	var machine Machine
+	if err := yaml.Unmarshal([]byte(`true`), &machine); err != nil || machine.Image != "" || machine.DockerLayerCaching != nil {
+		t.Errorf("yaml.Unmarshal() into Machine = %+v, %v, want the default machine", machine, err)
+	}
+
+	var executor ReusedExecutor
+	if err := yaml.Unmarshal([]byte(`golang`), &executor); err != nil || executor.Name != "golang" {
+		t.Errorf("yaml.Unmarshal() into ReusedExecutor = %+v, %v, want golang", executor, err)
+	}
+
+	var orb ConfigOrbImport
+	if err := yaml.Unmarshal([]byte(`circleci/node@5.0.2`), &orb); err != nil || orb.OrbImport != "circleci/node@5.0.2" {
+		t.Errorf("yaml.Unmarshal() into ConfigOrbImport = %+v, %v, want circleci/node@5.0.2", orb, err)
+	}
+
+	var env Environment
+	if err := yaml.Unmarshal([]byte(`[{CGO_ENABLED: 0}, {GOFLAGS: -mod=readonly}]`), &env); err != nil || env["CGO_ENABLED"] != "0" || env["GOFLAGS"] != "-mod=readonly" {
+		t.Errorf("yaml.Unmarshal() into Environment = %v, %v, want both variables", env, err)
+	}
+
+	b, err := yaml.Marshal(&branches)
+	if err != nil || string(b) != "only:\n    - main\n" {
+		t.Errorf("yaml.Marshal() of Branches = %q, %v, want only as a list", b, err)
+	}
+}
+
+func TestDecoderDisallowUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	config := `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a, foo: 1}]
+    steps:
+      - checkout
+      - when:
+          condition: true
+          steps:
+            - run: {command: make, comand: make}
+workflows:
+  main:
+    jobs: [build]
+`
+	if _, err := NewDecoder(strings.NewReader(config)).Decode(); err != nil {
+		t.Errorf("Decode() error = %v", err)
+	}
+
+	d := NewDecoder(strings.NewReader(config))
+	d.DisallowUnknownFields()
+	_, err := d.Decode()
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Decode() error = %v, want 2 unknown fields", err)
+	}
+	want := []string{
+		"/jobs/build/docker/0/foo",
+		"/jobs/build/steps/1/when/steps/0/run/comand",
+	}
+	for i, err := range errs {
+		if e, ok := err.(*UnknownFieldError); !ok || e.Path != want[i] {
+			t.Errorf("Decode() error %d = %#v, want an *UnknownFieldError at %s", i, err, want[i])
+		}
+	}
+	if e, ok := errs[1].(*UnknownFieldError); ok && e.Suggestion != "command" {
+		t.Errorf("Suggestion = %q, want %q", e.Suggestion, "command")
+	}
+}
+
+func TestValidateDisallowUnknownFields(t *testing.T) {
+	config := `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - run: {command: make, comand: make}
+workflows:
+  main:
+    jobs: [build]
+`
+	runValidateTests(t, new(Validator), []validateTest{
+		{name: "ignored", config: config},
+	})
+	runValidateTests(t, &Validator{DisallowUnknownFields: true}, []validateTest{
+		{
+			name:   "reported",
+			config: config,
+			want:   []string{`7:30: error: /jobs/build/steps/0/run: unknown field "comand", did you mean "command"?`},
+		},
+	})
+}
+
+func TestValidateDisallowUnknownFieldsKnownKeys(t *testing.T) {
+	runValidateTests(t, &Validator{DisallowUnknownFields: true, JSONSchema: true}, []validateTest{
+		{
+			name: "triggers, matrix alias and exclude, and restore_cache key",
+			config: `
+version: 2.1
+jobs:
+  test:
+    parameters:
+      go: {type: string}
+      os: {type: string}
+    docker: [{image: "cimg/go:<< parameters.go >>"}]
+    steps:
+      - restore_cache: {key: v1-deps}
+      - checkout
+workflows:
+  main:
+    jobs:
+      - test:
+          matrix:
+            alias: test-all
+            parameters:
+              go: ["1.17", "1.16"]
+              os: [linux, darwin]
+            exclude:
+              - {go: "1.16", os: darwin}
+  nightly:
+    triggers:
+      - schedule:
+          cron: "0 3 * * *"
+          filters:
+            branches: {only: [main]}
+    jobs:
+      - test: {go: "1.17", os: linux}
+`,
+		},
+	})
+}
@@ -0,0 +1,404 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MissingFieldError a required field is not present.
+type MissingFieldError struct {
+	// JSON pointer to where the field is expected, e.g. "/jobs/build/steps/3/run/command".
+	Path string
+
+	// Name of the missing field.
+	Field string
+}
+
+func missingField(field string) *MissingFieldError {
+	return &MissingFieldError{Path: jsonPointer(field), Field: field}
+}
+
+func (e *MissingFieldError) Error() string {
+	return withParentPath(e.Path, fmt.Sprintf("%q is required but was not present", e.Field))
+}
+
+// UnknownFieldError a field that is not part of the schema of a closed object.
+//
+// The UnmarshalJSON methods ignore unknown fields, as encoding/json does. Decoder and Validator report them only if
+// they are configured to disallow unknown fields.
+type UnknownFieldError struct {
+	// JSON pointer to the unknown field, e.g. "/jobs/build/steps/3/run/comand".
+	Path string
+
+	// Name of the unknown field.
+	Field string
+
+	// The known field closest to Field, if any is close enough to be a likely misspelling.
+	Suggestion string
+}
+
+// unknownField returns an UnknownFieldError for field, suggesting the closest of the known fields.
+func unknownField(field string, known ...string) *UnknownFieldError {
+	return &UnknownFieldError{
+		Path:       jsonPointer(field),
+		Field:      field,
+		Suggestion: suggest(field, known),
+	}
+}
+
+func (e *UnknownFieldError) Error() string {
+	return withParentPath(e.Path, fmt.Sprintf("unknown field %q%s", e.Field, didYouMean(e.Suggestion)))
+}
+
+// TypeMismatchError a value has a different JSON type than the schema requires.
+type TypeMismatchError struct {
+	// JSON pointer to the value.
+	Path string
+
+	// Expected the JSON type required by the schema, e.g. "string" or "object".
+	Expected string
+
+	// Actual the JSON type of the value.
+	Actual string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return withPath(e.Path, fmt.Sprintf("expected %s but got %s", e.Expected, e.Actual))
+}
+
+// InvalidValueError a value has the right type but is not allowed by the schema or by CircleCI.
+type InvalidValueError struct {
+	// JSON pointer to the value.
+	Path string
+
+	// The offending value, if any.
+	Value interface{}
+
+	// Why the value is invalid.
+	Reason string
+
+	// A valid value close to Value, if any is close enough to be a likely misspelling.
+	Suggestion string
+}
+
+func (e *InvalidValueError) Error() string {
+	if e.Value == nil {
+		return withPath(e.Path, e.Reason+didYouMean(e.Suggestion))
+	}
+	return withPath(e.Path, fmt.Sprintf("invalid value %s: %s%s", formatValue(e.Value), e.Reason, didYouMean(e.Suggestion)))
+}
+
+func didYouMean(suggestion string) string {
+	if suggestion == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", suggestion)
+}
+
+func withPath(path, msg string) string {
+	if path == "" {
+		return msg
+	}
+	return path + ": " + msg
+}
+
+func withParentPath(path, msg string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		path = path[:i]
+	}
+	return withPath(path, msg)
+}
+
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// unknownFields keeps the keys of an object, and of the objects nested in it, that are not fields of the type it was decoded into.
+//
+// Unknown keys are not errors of UnmarshalJSON, so that json.Unmarshal and yaml.Unmarshal ignore them as encoding/json does.
+// A Decoder reports them only if DisallowUnknownFields is called.
+type unknownFields struct {
+	unknown *Errors
+}
+
+// unknownFieldHolder a decoded value keeping its unknown keys. See unknownFields.
+type unknownFieldHolder interface {
+	takeUnknownFields() Errors
+}
+
+// keepUnknownFields keeps the *UnknownFieldError elements of err, in place of the ones kept before, and returns the other errors.
+func (u *unknownFields) keepUnknownFields(err error) error {
+	var all, errs, unknown Errors
+	if err != nil {
+		all.add(err)
+	}
+	for _, err := range all {
+		if _, ok := err.(*UnknownFieldError); ok {
+			unknown = append(unknown, err)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	u.unknown = nil
+	if len(unknown) > 0 {
+		u.unknown = &unknown
+	}
+	return errs.err()
+}
+
+// takeUnknownFields returns the unknown keys kept, and forgets them.
+func (u *unknownFields) takeUnknownFields() Errors {
+	if u.unknown == nil {
+		return nil
+	}
+	unknown := *u.unknown
+	u.unknown = nil
+	return unknown
+}
+
+// errorPath returns the JSON pointer carried by err, or the empty string if it has none.
+func errorPath(err error) string {
+	switch e := err.(type) {
+	case *MissingFieldError:
+		return e.Path
+	case *UnknownFieldError:
+		return e.Path
+	case *TypeMismatchError:
+		return e.Path
+	case *InvalidValueError:
+		return e.Path
+	}
+	return ""
+}
+
+// locate returns err with the JSON pointer prefix prepended to its path.
+//
+// Errors from encoding/json are converted to a TypeMismatchError, and any other error without a path to an InvalidValueError.
+// The elements of an Errors are located one by one.
+func locate(prefix string, err error) error {
+	switch e := err.(type) {
+	case Errors:
+		for i := range e {
+			e[i] = locate(prefix, e[i])
+		}
+		return e
+	case *MissingFieldError:
+		e.Path = prefix + e.Path
+		return e
+	case *UnknownFieldError:
+		e.Path = prefix + e.Path
+		return e
+	case *TypeMismatchError:
+		e.Path = prefix + e.Path
+		return e
+	case *InvalidValueError:
+		e.Path = prefix + e.Path
+		return e
+	case *json.UnmarshalTypeError:
+		var segments []string
+		for _, s := range strings.Split(e.Field, ".") {
+			if s != "" {
+				segments = append(segments, s)
+			}
+		}
+		actual := e.Value
+		if i := strings.IndexByte(actual, ' '); i >= 0 {
+			actual = actual[:i]
+		}
+		if actual == "bool" {
+			actual = "boolean"
+		}
+		return &TypeMismatchError{
+			Path:     prefix + jsonPointer(segments...),
+			Expected: jsonTypeName(e.Type),
+			Actual:   actual,
+		}
+	}
+	return &InvalidValueError{Path: prefix, Reason: err.Error()}
+}
+
+// jsonTypeName returns the name of the JSON type that t is decoded from.
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "value"
+}
+
+// Errors a list of errors reported together, such as every violation found while decoding a config.
+//
+// The elements are usually a *MissingFieldError, *UnknownFieldError, *TypeMismatchError or *InvalidValueError.
+//
+// errors.Is and errors.As match an Errors value if they match any of its elements.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any error in e matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in e that matches target, and if so, sets target to that error value and returns true.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add appends err to e. The elements of a nested Errors are appended one by one, so that e stays flat.
+func (e *Errors) add(err error) {
+	if nested, ok := err.(Errors); ok {
+		*e = append(*e, nested...)
+		return
+	}
+	*e = append(*e, err)
+}
+
+// addAt appends err, located under the JSON pointer segment seg, to e.
+func (e *Errors) addAt(seg string, err error) {
+	e.add(locate(jsonPointer(seg), err))
+}
+
+// err returns e sorted by path, or nil if e is empty.
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	sort.SliceStable(e, func(i, j int) bool {
+		pi, pj := errorPath(e[i]), errorPath(e[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return e[i].Error() < e[j].Error()
+	})
+	return e
+}
+
+// unmarshalValue decodes the JSON value b into the value pointed to by v.
+//
+// Unlike json.Unmarshal, the elements of slices and maps are decoded one by one, so every invalid element is reported instead of only the first one.
+// The unknown keys kept by the decoded value are returned too, for the object holding it to keep. See unknownFields.
+func unmarshalValue(b []byte, v interface{}) error {
+	err := decodeValue(b, v)
+	unknown := takeUnknownFieldsOf(v)
+	if len(unknown) == 0 {
+		return err
+	}
+	var errs Errors
+	if err != nil {
+		errs.add(err)
+	}
+	errs = append(errs, unknown...)
+	return errs.err()
+}
+
+// takeUnknownFieldsOf takes the unknown keys kept by the value v points to, or by the value that one points to in turn.
+func takeUnknownFieldsOf(v interface{}) Errors {
+	if h, ok := v.(unknownFieldHolder); ok {
+		return h.takeUnknownFields()
+	}
+	rv := reflect.ValueOf(v).Elem()
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && !rv.IsNil() {
+		if h, ok := rv.Interface().(unknownFieldHolder); ok {
+			return h.takeUnknownFields()
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes the JSON value b into the value pointed to by v. See unmarshalValue.
+func decodeValue(b []byte, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	if string(b) == "null" {
+		return json.Unmarshal(b, v)
+	}
+	if _, ok := v.(json.Unmarshaler); ok {
+		return json.Unmarshal(b, v)
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// base64 encoded []byte
+			return json.Unmarshal(b, v)
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(b, &items); err != nil {
+			return json.Unmarshal(b, v)
+		}
+		var errs Errors
+		s := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := unmarshalValue(item, s.Index(i).Addr().Interface()); err != nil {
+				errs.addAt(strconv.Itoa(i), err)
+			}
+		}
+		rv.Set(s)
+		return errs.err()
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return json.Unmarshal(b, v)
+		}
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(b, &items); err != nil {
+			return json.Unmarshal(b, v)
+		}
+		var errs Errors
+		m := reflect.MakeMapWithSize(rv.Type(), len(items))
+		for k, item := range items {
+			elem := reflect.New(rv.Type().Elem())
+			if err := unmarshalValue(item, elem.Interface()); err != nil {
+				errs.addAt(k, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem.Elem())
+		}
+		rv.Set(m)
+		return errs.err()
+	}
+	return json.Unmarshal(b, v)
+}
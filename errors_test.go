@@ -0,0 +1,77 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestUnmarshalReportsEveryMissingField(t *testing.T) {
+	t.Parallel()
+
+	var cfg CircleCIConfigSchema
+	err := json.Unmarshal([]byte(`{"jobs":{"build":{"docker":[{}]},"test":{"executor":"e"}},"workflows":{"main":{},"nightly":{"jobs":[{"build":{"filters":{"branches":{}}}}]}}}`), &cfg)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("json.Unmarshal() error = %#v, want Errors", err)
+	}
+	want := []struct{ path, field string }{
+		{"/jobs/build/docker/0/image", "image"},
+		{"/jobs/build/steps", "steps"},
+		{"/jobs/test/steps", "steps"},
+		{"/version", "version"},
+		{"/workflows/main/jobs", "jobs"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("json.Unmarshal() error =\n%v\nwant %d missing fields", err, len(want))
+	}
+	for i, err := range errs {
+		e, ok := err.(*MissingFieldError)
+		if !ok || e.Path != want[i].path || e.Field != want[i].field {
+			t.Errorf("error %d = %#v, want %q missing at %q", i, err, want[i].field, want[i].path)
+		}
+	}
+	if cfg.Jobs == nil || len(cfg.Jobs.AdditionalProperties) != 2 {
+		t.Errorf("jobs were not decoded despite the missing fields")
+	}
+	if cfg.Workflows == nil || len(cfg.Workflows.AdditionalProperties) != 2 {
+		t.Errorf("workflows were not decoded despite the missing fields")
+	}
+}
+
+func TestUnmarshalErrorTypes(t *testing.T) {
+	t.Parallel()
+
+	var cfg CircleCIConfigSchema
+	err := json.Unmarshal([]byte(`{
+		"version": 2.1,
+		"jobs": {"a/b": {"docker": [{"image": "a"}], "steps": [{"checkout": {}}]}},
+		"workflows": {"main": {"jobs": [{"a/b": {"requires": true}}]}, "nightly": {"jobs": 3}}
+	}`), &cfg)
+
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("json.Unmarshal() error = %#v, want 2 errors", err)
+	}
+	want := []TypeMismatchError{
+		// "/" in a key is escaped as "~1"
+		{Path: "/workflows/main/jobs/0/a~1b/requires", Expected: "string or array", Actual: "boolean"},
+		{Path: "/workflows/nightly/jobs", Expected: "array", Actual: "number"},
+	}
+	for i, err := range errs {
+		if e, ok := err.(*TypeMismatchError); !ok || *e != want[i] {
+			t.Errorf("error %d = %#v, want %+v", i, err, want[i])
+		}
+	}
+
+	var mismatch *TypeMismatchError
+	if !errors.As(err, &mismatch) || mismatch != errs[0] {
+		t.Errorf("errors.As() = %v, want the first error", mismatch)
+	}
+	if got, want := err.Error(), "/workflows/main/jobs/0/a~1b/requires: expected string or array but got boolean\n/workflows/nightly/jobs: expected array but got number"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
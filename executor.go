@@ -0,0 +1,181 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Executor the execution environment of a Job: a *DockerExecutor, *MachineExecutor, *MacOSExecutor, *WindowsExecutor,
+// or a *ReusedExecutor referring to a reusable executor of the config.
+type Executor interface {
+	json.Marshaler
+
+	// ExecutorType returns the kind of execution environment: "docker", "machine", "macos", "windows" or, for a
+	// *ReusedExecutor, "reused".
+	ExecutorType() string
+}
+
+func (r *DockerExecutor) ExecutorType() string  { return "docker" }
+func (r *MachineExecutor) ExecutorType() string { return "machine" }
+func (r *MacOSExecutor) ExecutorType() string   { return "macos" }
+func (r *WindowsExecutor) ExecutorType() string { return "windows" }
+func (r *ReusedExecutor) ExecutorType() string  { return "reused" }
+
+// decodeJobExecutor decodes the executor of a Job, choosing its concrete type from the fields present:
+// "xcode" selects a *MacOSExecutor, "serviceImages" or an image object a *DockerExecutor, and an image name a
+// *WindowsExecutor or *MachineExecutor, depending on whether the image or resource class is a Windows one.
+// A name, or an object holding only a name and arguments, selects a *ReusedExecutor.
+//
+// Any other value is rejected with an *InvalidValueError.
+func decodeJobExecutor(b []byte) (Executor, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	var executor Executor
+	switch e := v.(type) {
+	case string:
+		executor = new(ReusedExecutor)
+	case map[string]interface{}:
+		_, hasXcode := e["xcode"]
+		_, hasServiceImages := e["serviceImages"]
+		image, imageIsName := e["image"].(string)
+		_, imageIsObject := e["image"].(map[string]interface{})
+		_, hasName := e["name"]
+		switch {
+		case hasXcode:
+			executor = new(MacOSExecutor)
+		case hasServiceImages, imageIsObject:
+			executor = new(DockerExecutor)
+		case imageIsName:
+			resourceClass, _ := e["resourceClass"].(string)
+			if strings.HasPrefix(image, "windows-") || strings.HasPrefix(resourceClass, "windows.") {
+				executor = new(WindowsExecutor)
+			} else {
+				executor = new(MachineExecutor)
+			}
+		case hasName:
+			executor = new(ReusedExecutor)
+		}
+	}
+	if executor == nil {
+		return nil, &InvalidValueError{
+			Reason: "not a known executor; expected a docker, machine, macOS or Windows executor, or the name of a reusable executor",
+		}
+	}
+	return executor, unmarshalValue(b, executor)
+}
+
+// executorKeys the keys of a job or executor that select its execution environment.
+var executorKeys = []string{"docker", "machine", "macos"}
+
+// executorKinds returns the keys of fields that select an execution environment, in the order of executorKeys.
+func executorKinds(fields map[string]json.RawMessage) []string {
+	var kinds []string
+	for _, k := range executorKeys {
+		if _, ok := fields[k]; ok {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// conflictingKeys returns the error for an object that sets both of the mutually exclusive keys a and b.
+func conflictingKeys(a, b string) error {
+	return &InvalidValueError{Reason: fmt.Sprintf("%q and %q cannot be used together", a, b)}
+}
+
+// decodeExecutor decodes fields, the execution environment selected by kind, into a *DockerExecutorSchema, *MachineExecutorSchema,
+// *MacOSExecutorSchema or *WindowsExecutorSchema.
+//
+// The returned value is never nil, even if decoding failed.
+func decodeExecutor(kind string, fields map[string]json.RawMessage) (interface{}, error) {
+	var executor interface{}
+	switch {
+	case kind == "docker":
+		executor = new(DockerExecutorSchema)
+	case kind == "macos":
+		executor = new(MacOSExecutorSchema)
+	case isWindowsExecutor(fields):
+		executor = new(WindowsExecutorSchema)
+	default:
+		executor = new(MachineExecutorSchema)
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return executor, err
+	}
+	return executor, unmarshalValue(b, executor)
+}
+
+// isWindowsExecutor reports whether the fields of a "machine" execution environment describe a Windows virtual machine.
+func isWindowsExecutor(fields map[string]json.RawMessage) bool {
+	var resourceClass string
+	if err := json.Unmarshal(fields["resource_class"], &resourceClass); err == nil && strings.HasPrefix(resourceClass, "windows.") {
+		return true
+	}
+	var machine struct {
+		Image string `json:"image"`
+	}
+	if err := json.Unmarshal(fields["machine"], &machine); err == nil && strings.HasPrefix(machine.Image, "windows-") {
+		return true
+	}
+	return false
+}
+
+// withResourceClass returns a copy of the execution environment executor with its resource class, and the shell of a Windows one, replaced.
+func withResourceClass(executor interface{}, resourceClass, shell string) interface{} {
+	switch e := executor.(type) {
+	case *DockerExecutorSchema:
+		c := *e
+		c.ResourceClass = resourceClass
+		return &c
+	case *MachineExecutorSchema:
+		c := *e
+		c.ResourceClass = resourceClass
+		return &c
+	case *MacOSExecutorSchema:
+		c := *e
+		c.ResourceClass = resourceClass
+		return &c
+	case *WindowsExecutorSchema:
+		c := *e
+		c.ResourceClass = resourceClass
+		c.Shell = shell
+		return &c
+	}
+	return executor
+}
+
+// writeObjectFields writes the fields of the JSON object encoding of v to buf, in the order v writes them.
+// comma reports whether buf already holds a field; the returned value reports whether it does afterwards.
+func writeObjectFields(buf *bytes.Buffer, comma bool, v interface{}) (bool, error) {
+	tmp, err := json.Marshal(v)
+	if err != nil {
+		return comma, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(tmp, &fields); err != nil {
+		return comma, err
+	}
+	for _, k := range objectKeys(tmp) {
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return comma, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		buf.Write(fields[k])
+		comma = true
+	}
+	return comma, nil
+}
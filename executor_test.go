@@ -0,0 +1,126 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeExecutors(t *testing.T) {
+	t.Parallel()
+
+	config := `
+version: 2.1
+executors:
+  go:
+    docker: [{image: cimg/go:1.17}]
+    working_directory: /src
+  vm:
+    machine: {image: ubuntu-2004:202107-02}
+  mac:
+    macos: {xcode: 13.0.0}
+  win:
+    machine: {image: windows-server-2019-vs2019:stable}
+    resource_class: windows.medium
+jobs:
+  build:
+    executor: go
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`
+	doc, err := NewDecoder(strings.NewReader(config)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	executors := doc.Config.Executors.AdditionalProperties
+	if e := executors["go"]; e == nil || e.Docker == nil || e.Docker.Docker[0].Image != "cimg/go:1.17" || e.WorkingDirectory != "/src" {
+		t.Errorf("executor go = %#v, want a docker executor", e)
+	}
+	if e := executors["vm"]; e == nil || e.Machine == nil {
+		t.Errorf("executor vm = %#v, want a machine executor", e)
+	}
+	if e := executors["mac"]; e == nil || e.MacOS == nil {
+		t.Errorf("executor mac = %#v, want a macos executor", e)
+	}
+	if e := executors["win"]; e == nil || e.Windows == nil || e.Machine != nil {
+		t.Errorf("executor win = %#v, want a windows executor", e)
+	}
+}
+
+func TestValidateExecutors(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "no such executor",
+			config: `
+version: 2.1
+executors:
+  go:
+    docker: [{image: a}]
+  none:
+    working_directory: /src
+jobs:
+  build:
+    executor: {name: golang}
+    steps: [checkout]
+  test:
+    executor: go
+    steps: [checkout]
+  lint:
+    executor: nosuch
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build, test, lint]
+`,
+			want: []string{
+				`6:3: error: /executors/none: one of "docker", "machine" or "macos" is required`,
+				`10:5: error: /jobs/build/executor: invalid value "golang": no such executor, did you mean "go"?`,
+				`16:5: error: /jobs/lint/executor: invalid value "nosuch": no such executor`,
+			},
+		},
+	})
+}
+
+func TestDecodeJobExecutor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		executor string
+		want     string
+	}{
+		{executor: `{"image": {"image": "cimg/go:1.17"}, "resourceClass": "medium", "serviceImages": []}`, want: "docker"},
+		{executor: `{"image": "ubuntu-2004:202107-02", "resourceClass": "medium"}`, want: "machine"},
+		{executor: `{"image": "windows-server-2019-vs2019:stable", "resourceClass": "windows.medium"}`, want: "windows"},
+		{executor: `{"xcode": "13.0.0", "resourceClass": "medium"}`, want: "macos"},
+		{executor: `"go"`, want: "reused"},
+		{executor: `{"name": "go", "version": "1.17"}`, want: "reused"},
+	}
+	for _, tt := range tests {
+		var job Job
+		if err := json.Unmarshal([]byte(`{"name": "build", "steps": [], "executor": `+tt.executor+`}`), &job); err != nil {
+			t.Errorf("json.Unmarshal() of executor %s error = %v", tt.executor, err)
+			continue
+		}
+		if job.Executor == nil || job.Executor.ExecutorType() != tt.want {
+			t.Errorf("executor %s decoded as %#v, want a %s executor", tt.executor, job.Executor, tt.want)
+		}
+	}
+
+	var job Job
+	err := json.Unmarshal([]byte(`{"name": "build", "steps": [], "executor": {"resourceClass": "medium"}}`), &job)
+	var invalid *InvalidValueError
+	if !errors.As(err, &invalid) || invalid.Path != "/executor" {
+		t.Errorf("json.Unmarshal() of an unknown executor error = %v, want an *InvalidValueError at /executor", err)
+	}
+
+	b, err := json.Marshal(&ReusedExecutor{Name: "go", Arguments: map[string]interface{}{"version": "1.17"}})
+	if err != nil || string(b) != `{"name":"go","version":"1.17"}` {
+		t.Errorf("json.Marshal() of a reused executor = %s, %v", b, err)
+	}
+}
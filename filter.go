@@ -0,0 +1,156 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// javaOnlySyntax the constructs of Java regular expressions, which CircleCI uses for filter specifiers, that Go's regexp package does not support.
+var javaOnlySyntax = []struct {
+	re   *regexp.Regexp
+	name string
+}{
+	{regexp.MustCompile(`\(\?<?[=!]`), "lookaround"},
+	{regexp.MustCompile(`\(\?>`), "atomic groups"},
+	{regexp.MustCompile(`[*+?}]\+`), "possessive quantifiers"},
+	{regexp.MustCompile(`\\[1-9]|\\k<`), "backreferences"},
+	{regexp.MustCompile(`\\p\{(?:java|Is|In)`), "Java character classes"},
+	{regexp.MustCompile(`\\[GZ]`), `\G and \Z boundaries`},
+}
+
+// javaSyntax returns the name of the Java-only construct used by the regular expression pattern, or "" if it uses none.
+func javaSyntax(pattern string) string {
+	for _, s := range javaOnlySyntax {
+		if s.re.MatchString(pattern) {
+			return s.name
+		}
+	}
+	return ""
+}
+
+// filterPattern returns the regular expression of the filter specifier spec, and whether spec is one, that is whether it is wrapped in slashes as in "/^release-.*/".
+func filterPattern(spec string) (string, bool) {
+	if len(spec) >= 2 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/") {
+		return spec[1 : len(spec)-1], true
+	}
+	return "", false
+}
+
+// matchSpecifier reports whether the branch or tag name s matches the filter specifier spec.
+//
+// A regular expression must match the whole of s. A specifier that is not a valid Go regular expression matches nothing.
+func matchSpecifier(spec, s string) bool {
+	pattern, ok := filterPattern(spec)
+	if !ok {
+		return spec == s
+	}
+	re, err := compileMatchesPattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// matchAny reports whether s matches any of the filter specifiers specs.
+func matchAny(specs StringOrList, s string) bool {
+	for _, spec := range specs {
+		if matchSpecifier(spec, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a job with the filters r would run for a pipeline triggered by branch, or by tag if tag is not empty.
+//
+// A job is run for a branch unless it fails to match the "only" specifiers of Branches, or matches the "ignore" ones.
+// As in CircleCI, a job is run for a tag only if it has explicit tag filters: the tag must match the "only" specifiers
+// of Tags and not match the "ignore" ones; Branches is not considered. A nil r is a job without filters.
+func (r *WorkflowFilterSchema) Matches(branch, tag string) bool {
+	if tag != "" {
+		if r == nil || r.Tags == nil || (len(r.Tags.Only) == 0 && len(r.Tags.Ignore) == 0) {
+			return false
+		}
+		if len(r.Tags.Only) > 0 && !matchAny(r.Tags.Only, tag) {
+			return false
+		}
+		return !matchAny(r.Tags.Ignore, tag)
+	}
+
+	if r == nil || r.Branches == nil {
+		return true
+	}
+	if len(r.Branches.Only) > 0 && !matchAny(r.Branches.Only, branch) {
+		return false
+	}
+	return !matchAny(r.Branches.Ignore, branch)
+}
+
+// checkFilters reports the branch and tag filter specifiers of workflow jobs that are invalid regular expressions.
+// Valid Java regular expressions using syntax Go does not support, such as lookaround, are reported as warnings,
+// as they cannot be checked.
+//
+// Specifiers containing a parameter expression are not checked.
+func checkFilters(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Workflows == nil {
+		return
+	}
+	for _, wfName := range sortedWorkflowNames(cfg.Workflows) {
+		wf := cfg.Workflows.AdditionalProperties[wfName]
+		if wf == nil {
+			continue
+		}
+		for i, job := range wf.Jobs {
+			if job == nil {
+				continue
+			}
+			for _, name := range sortedWorkflowJobNames(job) {
+				item := job.AdditionalProperties[name]
+				if item == nil || item.Filters == nil {
+					continue
+				}
+				ptr := jsonPointer("workflows", wfName, "jobs", strconv.Itoa(i), name, "filters")
+				if b := item.Filters.Branches; b != nil {
+					checkSpecifiers(report, ptr+jsonPointer("branches", "only"), b.Only)
+					checkSpecifiers(report, ptr+jsonPointer("branches", "ignore"), b.Ignore)
+				}
+				if t := item.Filters.Tags; t != nil {
+					checkSpecifiers(report, ptr+jsonPointer("tags", "only"), t.Only)
+					checkSpecifiers(report, ptr+jsonPointer("tags", "ignore"), t.Ignore)
+				}
+			}
+		}
+	}
+}
+
+// checkSpecifiers reports the regular expressions of specs, found at ptr, that do not compile.
+func checkSpecifiers(report *Report, ptr string, specs StringOrList) {
+	for i, spec := range specs {
+		pattern, ok := filterPattern(spec)
+		if !ok || isParameterExpression(spec) {
+			continue
+		}
+		_, err := regexp.Compile(pattern)
+		if err == nil {
+			continue
+		}
+		specPtr := ptr + jsonPointer(strconv.Itoa(i))
+		if syntax := javaSyntax(pattern); syntax != "" {
+			report.add(SeverityWarning, &InvalidValueError{
+				Path:   specPtr,
+				Value:  spec,
+				Reason: "regular expression uses " + syntax + ", which is Java-only syntax and cannot be checked",
+			})
+			continue
+		}
+		report.add(SeverityError, &InvalidValueError{
+			Path:   specPtr,
+			Value:  spec,
+			Reason: "invalid regular expression: " + regexpErrorReason(err),
+		})
+	}
+}
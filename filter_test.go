@@ -0,0 +1,68 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import "testing"
+
+func TestWorkflowFilterMatches(t *testing.T) {
+	t.Parallel()
+
+	release := &WorkflowFilterSchema{
+		Branches: &Branches{Only: StringOrList{"main", "/release-.*/"}, Ignore: StringOrList{"release-old"}},
+		Tags:     &Tags{Only: StringOrList{"/^v[0-9.]+$/"}},
+	}
+	tests := []struct {
+		name    string
+		filters *WorkflowFilterSchema
+		branch  string
+		tag     string
+		want    bool
+	}{
+		{name: "no filters, branch", filters: nil, branch: "any", want: true},
+		{name: "no filters, tag", filters: nil, tag: "v1", want: false},
+		{name: "exact branch", filters: release, branch: "main", want: true},
+		{name: "regexp branch", filters: release, branch: "release-1.0", want: true},
+		{name: "regexp matches the whole name", filters: release, branch: "my-release-1.0", want: false},
+		{name: "ignored branch", filters: release, branch: "release-old", want: false},
+		{name: "other branch", filters: release, branch: "feature", want: false},
+		{name: "matching tag", filters: release, tag: "v1.2.0", want: true},
+		{name: "other tag", filters: release, tag: "nightly", want: false},
+		{name: "branch filters only, tag", filters: &WorkflowFilterSchema{Branches: release.Branches}, tag: "v1", want: false},
+		{name: "invalid regexp", filters: &WorkflowFilterSchema{Branches: &Branches{Only: StringOrList{"/(/"}}}, branch: "(", want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.filters.Matches(tt.branch, tt.tag); got != tt.want {
+			t.Errorf("%s: Matches(%q, %q) = %t, want %t", tt.name, tt.branch, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "invalid and Java-only regular expressions",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build:
+          filters:
+            branches:
+              only: [main, /release-(.*/]
+              ignore: /(?!main).*/
+            tags:
+              only: /<< pipeline.parameters.tag >>/
+`,
+			want: []string{
+				"13:28: error: /workflows/main/jobs/0/build/filters/branches/only/1: invalid value \"/release-(.*/\": invalid regular expression: missing closing ): `release-(.*`",
+				`14:15: warning: /workflows/main/jobs/0/build/filters/branches/ignore/0: invalid value "/(?!main).*/": regular expression uses lookaround, which is Java-only syntax and cannot be checked`,
+			},
+		},
+	})
+}
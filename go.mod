@@ -1,3 +1,7 @@
 module github.com/zchee/circleci-validator
 
 go 1.17
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require github.com/santhosh-tekuri/jsonschema/v5 v5.3.0
@@ -0,0 +1,392 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WorkflowGraph the jobs of a workflow and the dependencies between them given by their "requires".
+type WorkflowGraph struct {
+	// Workflow the name of the workflow.
+	Workflow string
+
+	// Nodes the jobs of the workflow, in the order they are listed.
+	Nodes []*WorkflowNode
+
+	// Edges the dependencies between the jobs, in the order of Nodes and of their "requires" entries.
+	Edges []*WorkflowEdge
+
+	// Unresolved the "requires" entries that do not name a job of the workflow.
+	Unresolved []*WorkflowRequirement
+}
+
+// WorkflowNode a job of a workflow.
+type WorkflowNode struct {
+	// Name the name the job runs under in the workflow: its "name" if set, or else Job.
+	// For a job with a matrix, it is the name other jobs require to wait for every job of the matrix: the alias of the
+	// matrix if set, or else Job.
+	Name string
+
+	// MatrixNames the names the jobs of the matrix run under, one per combination of the matrix parameters that is not
+	// excluded; nil if the job has no matrix. See matrixNames.
+	MatrixNames []string
+
+	// Job the name of the job, as listed in the jobs of the workflow.
+	Job string
+
+	// Index the index of the job in the jobs of the workflow.
+	Index int
+
+	// Item the parameters of the job in the workflow, nil if it has none.
+	Item *WorkflowJobSchemaItem
+}
+
+// WorkflowEdge a dependency of the job To on the job From, which runs first.
+type WorkflowEdge struct {
+	From *WorkflowNode
+	To   *WorkflowNode
+
+	// requires the index of the "requires" entry of To naming From.
+	requires int
+}
+
+// WorkflowRequirement a "requires" entry of a job.
+type WorkflowRequirement struct {
+	Node *WorkflowNode
+
+	// Index the index of the entry in the "requires" of Node.
+	Index int
+
+	// Name the name of the required job.
+	Name string
+}
+
+// Graph returns the graph of the jobs of the workflow name, or nil if there is no such workflow.
+//
+// A "requires" entry names the jobs running under that name. A job with a matrix is named either by one of its
+// MatrixNames, as in "build-1.17", or by its Name, which waits for every job of the matrix; either way the entry depends
+// on the node of the job. Entries containing a parameter expression are not part of the graph.
+func (r *WorkflowSchema) Graph(name string) *WorkflowGraph {
+	wf := r.AdditionalProperties[name]
+	if wf == nil {
+		return nil
+	}
+
+	g := &WorkflowGraph{Workflow: name}
+	byName := make(map[string][]*WorkflowNode)
+	for i, job := range wf.Jobs {
+		if job == nil {
+			continue
+		}
+		for _, jobName := range sortedWorkflowJobNames(job) {
+			node := &WorkflowNode{Name: jobName, Job: jobName, Index: i, Item: job.AdditionalProperties[jobName]}
+			switch {
+			case node.Item != nil && node.Item.Matrix != nil:
+				if node.Item.Matrix.Alias != "" {
+					node.Name = node.Item.Matrix.Alias
+				}
+				node.MatrixNames = matrixNames(jobName, node.Item)
+			case node.Item != nil && node.Item.Name != "":
+				node.Name = node.Item.Name
+			}
+			g.Nodes = append(g.Nodes, node)
+			for _, name := range append([]string{node.Name}, node.MatrixNames...) {
+				if nodes := byName[name]; len(nodes) == 0 || nodes[len(nodes)-1] != node {
+					byName[name] = append(nodes, node)
+				}
+			}
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if node.Item == nil {
+			continue
+		}
+		for j, req := range node.Item.Requires {
+			if isParameterExpression(req) {
+				continue
+			}
+			required := byName[req]
+			if len(required) == 0 {
+				g.Unresolved = append(g.Unresolved, &WorkflowRequirement{Node: node, Index: j, Name: req})
+				continue
+			}
+			for _, from := range required {
+				g.Edges = append(g.Edges, &WorkflowEdge{From: from, To: node, requires: j})
+			}
+		}
+	}
+	return g
+}
+
+// matrixNames returns the names the jobs of the matrix of item, a job of a workflow listed as job, run under: the name of
+// item with each "<< matrix.param >>" replaced by the value of param, or, if item has no name, job followed by "-" and
+// the values of the parameters in the order of their names, as in "build-linux-1.17".
+//
+// Combinations of values matching an entry of the "exclude" of the matrix are not run, and have no name.
+func matrixNames(job string, item *WorkflowJobSchemaItem) []string {
+	params := make([]string, 0, len(item.Matrix.Parameters))
+	for name := range item.Matrix.Parameters {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+
+	combinations := []map[string]string{{}}
+	for _, param := range params {
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, v := range item.Matrix.Parameters[param] {
+				combination := make(map[string]string, len(c)+1)
+				for k, v := range c {
+					combination[k] = v
+				}
+				combination[param] = matrixValue(v)
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+
+	var names []string
+outer:
+	for _, c := range combinations {
+		for _, exclude := range item.Matrix.Exclude {
+			if matchesExclude(c, exclude) {
+				continue outer
+			}
+		}
+		if item.Name != "" {
+			names = append(names, matrixExpressionRe.ReplaceAllStringFunc(item.Name, func(expr string) string {
+				if v, ok := c[matrixExpressionRe.FindStringSubmatch(expr)[1]]; ok {
+					return v
+				}
+				return expr
+			}))
+			continue
+		}
+		name := job
+		for _, param := range params {
+			name += "-" + c[param]
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// matrixExpressionRe matches a reference to a matrix parameter, as in "<< matrix.go >>".
+var matrixExpressionRe = regexp.MustCompile(`<<\s*matrix\.([^\s<>]+)\s*>>`)
+
+// matchesExclude reports whether the combination of matrix values c holds every value of the "exclude" entry exclude.
+func matchesExclude(c map[string]string, exclude map[string]interface{}) bool {
+	for param, v := range exclude {
+		if c[param] != matrixValue(v) {
+			return false
+		}
+	}
+	return len(exclude) > 0
+}
+
+// matrixValue returns v, a value of a matrix parameter, as it appears in the names of the jobs of the matrix.
+func matrixValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// Requires returns the jobs node requires, in the order of its "requires" entries.
+func (g *WorkflowGraph) Requires(node *WorkflowNode) []*WorkflowNode {
+	var nodes []*WorkflowNode
+	for _, e := range g.Edges {
+		if e.To == node {
+			nodes = append(nodes, e.From)
+		}
+	}
+	return nodes
+}
+
+// RequiredBy returns the jobs requiring node, in the order of Nodes.
+func (g *WorkflowGraph) RequiredBy(node *WorkflowNode) []*WorkflowNode {
+	var nodes []*WorkflowNode
+	for _, e := range g.Edges {
+		if e.From == node {
+			nodes = append(nodes, e.To)
+		}
+	}
+	return nodes
+}
+
+// TopologicalOrder returns the jobs of g ordered so that every job comes after the jobs it requires, and otherwise in the order of Nodes.
+//
+// If the dependencies of some jobs form a cycle, it returns the jobs that can be ordered, and an *InvalidValueError naming the others.
+func (g *WorkflowGraph) TopologicalOrder() ([]*WorkflowNode, error) {
+	pending := make(map[*WorkflowNode]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		pending[e.To]++
+	}
+	order := make([]*WorkflowNode, 0, len(g.Nodes))
+	done := make(map[*WorkflowNode]bool, len(g.Nodes))
+	for len(order) < len(g.Nodes) {
+		progress := false
+		for _, node := range g.Nodes {
+			if done[node] || pending[node] > 0 {
+				continue
+			}
+			done[node] = true
+			order = append(order, node)
+			for _, next := range g.RequiredBy(node) {
+				pending[next]--
+			}
+			progress = true
+			break
+		}
+		if !progress {
+			var names []string
+			for _, node := range g.Nodes {
+				if !done[node] {
+					names = appendUnique(names, node.Name)
+				}
+			}
+			return order, &InvalidValueError{
+				Path:   jsonPointer("workflows", g.Workflow, "jobs"),
+				Reason: "cannot order jobs " + strings.Join(quoteAll(names), ", ") + "; their requires form a cycle",
+			}
+		}
+	}
+	return order, nil
+}
+
+// Cycles returns the cycles of the dependencies of g. Each cycle lists jobs that each require the next one, the last one
+// requiring the first; a job that requires itself is a cycle of one job.
+//
+// Every edge closing a cycle, found by following the "requires" of the jobs in the order of Nodes, yields one cycle.
+func (g *WorkflowGraph) Cycles() [][]*WorkflowNode {
+	var cycles [][]*WorkflowNode
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*WorkflowNode]int, len(g.Nodes))
+	var stack []*WorkflowNode
+	var visit func(node *WorkflowNode)
+	visit = func(node *WorkflowNode) {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, req := range g.Requires(node) {
+			switch state[req] {
+			case unvisited:
+				visit(req)
+			case visiting:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == req {
+						cycles = append(cycles, append([]*WorkflowNode(nil), stack[i:]...))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+	}
+	for _, node := range g.Nodes {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+	return cycles
+}
+
+// requiresPointer returns the JSON pointer to the "requires" entry i of node.
+func (g *WorkflowGraph) requiresPointer(node *WorkflowNode, i int) string {
+	return jsonPointer("workflows", g.Workflow, "jobs", strconv.Itoa(node.Index), node.Job, "requires", strconv.Itoa(i))
+}
+
+// edge returns the edge from the job from to the job to, or nil if to does not require from.
+func (g *WorkflowGraph) edge(from, to *WorkflowNode) *WorkflowEdge {
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to {
+			return e
+		}
+	}
+	return nil
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	return quoted
+}
+
+// checkRequires reports "requires" entries that do not name a job of their workflow, jobs that require themselves,
+// and jobs whose requires form a cycle.
+func checkRequires(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Workflows == nil {
+		return
+	}
+	var defined []string
+	if cfg.Jobs != nil {
+		for name := range cfg.Jobs.AdditionalProperties {
+			defined = append(defined, name)
+		}
+	}
+
+	for _, wfName := range sortedWorkflowNames(cfg.Workflows) {
+		g := cfg.Workflows.Graph(wfName)
+		if g == nil {
+			continue
+		}
+		var names []string
+		for _, node := range g.Nodes {
+			names = append(names, node.Name)
+			names = append(names, node.MatrixNames...)
+		}
+
+		for _, req := range g.Unresolved {
+			err := &InvalidValueError{
+				Path:  g.requiresPointer(req.Node, req.Index),
+				Value: req.Name,
+			}
+			if cfg.Jobs != nil && cfg.Jobs.AdditionalProperties[req.Name] != nil {
+				err.Reason = fmt.Sprintf("job is defined but not part of workflow %q", wfName)
+			} else {
+				err.Reason = fmt.Sprintf("not a job of workflow %q", wfName)
+				err.Suggestion = suggest(req.Name, append(names, defined...))
+			}
+			report.add(SeverityError, err)
+		}
+
+		for _, cycle := range g.Cycles() {
+			last := cycle[len(cycle)-1]
+			e := g.edge(cycle[0], last)
+			if e == nil {
+				continue
+			}
+			err := &InvalidValueError{
+				Path:  g.requiresPointer(last, e.requires),
+				Value: last.Item.Requires[e.requires],
+			}
+			if len(cycle) == 1 {
+				err.Reason = "job requires itself"
+			} else {
+				path := make([]string, 0, len(cycle)+1)
+				for _, node := range cycle {
+					path = append(path, strconv.Quote(node.Name))
+				}
+				err.Reason = "requires form a cycle: " + strings.Join(append(path, path[0]), " requires ")
+			}
+			report.add(SeverityError, err)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// decodeConfig decodes the config, failing t if it does not decode.
+func decodeConfig(t *testing.T, config string) *CircleCIConfigSchema {
+	t.Helper()
+	doc, err := NewDecoder(strings.NewReader(config)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return doc.Config
+}
+
+// nodeNames returns the names of nodes.
+func nodeNames(nodes []*WorkflowNode) string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestWorkflowGraph(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodeConfig(t, `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test: {docker: [{image: a}], steps: [checkout]}
+  deploy: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - deploy:
+          requires: [test-1.17, build, << pipeline.parameters.extra >>, lint]
+      - test:
+          requires: [build]
+          matrix:
+            parameters:
+              go: ["1.17", "1.16"]
+      - build:
+          name: compile
+      - build
+`)
+	if g := cfg.Workflows.Graph("nightly"); g != nil {
+		t.Errorf("Graph(%q) = %v, want nil", "nightly", g)
+	}
+
+	g := cfg.Workflows.Graph("main")
+	if got, want := nodeNames(g.Nodes), "deploy test compile build"; got != want {
+		t.Errorf("Nodes = %s, want %s", got, want)
+	}
+	deploy, test := g.Nodes[0], g.Nodes[1]
+	if got, want := nodeNames(g.Requires(deploy)), "test build"; got != want {
+		t.Errorf("Requires(deploy) = %s, want %s", got, want)
+	}
+	if got, want := nodeNames(g.Requires(test)), "build"; got != want {
+		t.Errorf("Requires(test) = %s, want %s", got, want)
+	}
+	if got, want := nodeNames(g.RequiredBy(g.Nodes[3])), "deploy test"; got != want {
+		t.Errorf("RequiredBy(build) = %s, want %s", got, want)
+	}
+	if len(g.Unresolved) != 1 || g.Unresolved[0].Node != deploy || g.Unresolved[0].Index != 3 || g.Unresolved[0].Name != "lint" {
+		t.Errorf("Unresolved = %+v, want lint, the requires 3 of deploy", g.Unresolved)
+	}
+
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		t.Errorf("TopologicalOrder() error = %v", err)
+	}
+	if got, want := nodeNames(order), "compile build test deploy"; got != want {
+		t.Errorf("TopologicalOrder() = %s, want %s", got, want)
+	}
+	if cycles := g.Cycles(); len(cycles) != 0 {
+		t.Errorf("Cycles() = %v, want none", cycles)
+	}
+}
+
+func TestWorkflowGraphCycles(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodeConfig(t, `
+version: 2.1
+jobs:
+  a: {docker: [{image: a}], steps: [checkout]}
+  b: {docker: [{image: a}], steps: [checkout]}
+  c: {docker: [{image: a}], steps: [checkout]}
+  d: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - d
+      - a: {requires: [c]}
+      - b: {requires: [a, b]}
+      - c: {requires: [b, d]}
+`)
+	g := cfg.Workflows.Graph("main")
+
+	cycles := g.Cycles()
+	got := make([]string, len(cycles))
+	for i, cycle := range cycles {
+		got[i] = nodeNames(cycle)
+	}
+	if want := []string{"a c b", "b"}; strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Cycles() = %q, want %q", got, want)
+	}
+
+	order, err := g.TopologicalOrder()
+	if got, want := nodeNames(order), "d"; got != want {
+		t.Errorf("TopologicalOrder() = %s, want %s", got, want)
+	}
+	var invalid *InvalidValueError
+	if !errors.As(err, &invalid) || invalid.Path != "/workflows/main/jobs" {
+		t.Fatalf("TopologicalOrder() error = %#v, want an *InvalidValueError at /workflows/main/jobs", err)
+	}
+	if want := `cannot order jobs "a", "b", "c"; their requires form a cycle`; invalid.Reason != want {
+		t.Errorf("Reason = %q, want %q", invalid.Reason, want)
+	}
+}
+
+func TestWorkflowGraphMatrix(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodeConfig(t, `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test: {docker: [{image: a}], steps: [checkout]}
+  lint: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - build:
+          matrix:
+            parameters:
+              os: [linux, darwin]
+              go: ["1.17", 1.16]
+            exclude:
+              - {os: darwin, go: 1.16}
+      - test:
+          name: test-<< matrix.go >>
+          matrix:
+            alias: all-tests
+            parameters:
+              go: ["1.17"]
+      - lint:
+          requires: [build, build-1.17-linux, all-tests, test-1.17, test-1.16, build-e2e]
+`)
+	g := cfg.Workflows.Graph("main")
+	build, test, lint := g.Nodes[0], g.Nodes[1], g.Nodes[2]
+	if got, want := strings.Join(build.MatrixNames, " "), "build-1.17-linux build-1.17-darwin build-1.16-linux"; got != want {
+		t.Errorf("MatrixNames of build = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(test.MatrixNames, " "), "test-1.17"; got != want || test.Name != "all-tests" {
+		t.Errorf("test = %s with MatrixNames %s, want all-tests with %s", test.Name, got, want)
+	}
+	if lint.MatrixNames != nil {
+		t.Errorf("MatrixNames of lint = %q, want nil", lint.MatrixNames)
+	}
+	if got, want := nodeNames(g.Requires(lint)), "build build all-tests all-tests"; got != want {
+		t.Errorf("Requires(lint) = %s, want %s", got, want)
+	}
+	var unresolved []string
+	for _, req := range g.Unresolved {
+		unresolved = append(unresolved, req.Name)
+	}
+	if got, want := strings.Join(unresolved, " "), "test-1.16 build-e2e"; got != want {
+		t.Errorf("Unresolved = %s, want %s", got, want)
+	}
+}
+
+func TestValidateRequires(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "valid",
+			config: `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - build
+      - test: {requires: [build]}
+`,
+		},
+		{
+			name: "unresolved",
+			config: `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - test: {requires: [biuld]}
+  other:
+    jobs:
+      - build
+      - test: {requires: [lint]}
+`,
+			want: []string{
+				`9:27: error: /workflows/main/jobs/0/test/requires/0: invalid value "biuld": not a job of workflow "main", did you mean "build"?`,
+				`13:27: error: /workflows/other/jobs/1/test/requires/0: invalid value "lint": not a job of workflow "other"`,
+			},
+		},
+		{
+			name: "defined but not part of the workflow",
+			config: `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - test: {requires: [build]}
+`,
+			want: []string{
+				`9:27: error: /workflows/main/jobs/0/test/requires/0: invalid value "build": job is defined but not part of workflow "main"`,
+			},
+		},
+		{
+			name: "matrix",
+			config: `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test:
+    parameters:
+      go: {type: string}
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - test:
+          name: test-<< matrix.go >>
+          matrix:
+            parameters:
+              go: ["1.17", "1.16"]
+      - build: {requires: [test]}
+      - build: {name: build-1.17, requires: [test-1.17]}
+      - build: {name: build-1.15, requires: [test-1.15]}
+`,
+			want: []string{
+				`20:46: error: /workflows/main/jobs/3/build/requires/0: invalid value "test-1.15": not a job of workflow "main", did you mean "test"?`,
+			},
+		},
+		{
+			name: "cycles",
+			config: `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+  test: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - build: {requires: [test]}
+      - test: {requires: [test, build]}
+`,
+			want: []string{
+				`10:27: error: /workflows/main/jobs/1/test/requires/0: invalid value "test": job requires itself`,
+				`10:33: error: /workflows/main/jobs/1/test/requires/1: invalid value "build": requires form a cycle: "build" requires "test" requires "build"`,
+			},
+		},
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The kinds of job a workflow job may refer to.
+const (
+	// JobRefDefined a job defined under the jobs of the config.
+	JobRefDefined = "defined"
+
+	// JobRefOrb a job of an orb, named "alias/job".
+	JobRefOrb = "orb"
+
+	// JobRefApproval an approval job, whose JobType is "approval", which pauses the workflow until it is approved.
+	JobRefApproval = "approval"
+
+	// JobRefParameter a job named by a parameter expression, which is only known once the config is processed.
+	JobRefParameter = "parameter"
+
+	// JobRefUnresolved a job that is none of the above.
+	JobRefUnresolved = "unresolved"
+)
+
+// JobReference a job of a workflow, and what it refers to.
+type JobReference struct {
+	// Workflow the name of the workflow.
+	Workflow string
+
+	// Index the index of the job in the jobs of the workflow.
+	Index int
+
+	// Name the name of the job, as listed in the jobs of the workflow.
+	Name string
+
+	// Kind the kind of job Name refers to, one of JobRefDefined, JobRefOrb, JobRefApproval, JobRefParameter and JobRefUnresolved.
+	Kind string
+
+	// Job the job Name refers to, if Kind is JobRefDefined, or if it is JobRefOrb and the orb is an inline one.
+	Job *JobSchemaItem
+
+	// Orb the alias of the orb, if Kind is JobRefOrb.
+	Orb string
+
+	// Err why the job could not be resolved, if Kind is JobRefUnresolved.
+	Err error
+}
+
+// ResolveJobReferences returns every job of the workflows of cfg, in the order of the workflow names and of their jobs,
+// with what it refers to.
+//
+// A job of an orb imported from the registry is not checked beyond its orb alias, as its jobs are not known.
+func ResolveJobReferences(cfg *CircleCIConfigSchema) []*JobReference {
+	if cfg.Workflows == nil {
+		return nil
+	}
+	var refs []*JobReference
+	for _, wfName := range sortedWorkflowNames(cfg.Workflows) {
+		wf := cfg.Workflows.AdditionalProperties[wfName]
+		if wf == nil {
+			continue
+		}
+		for i, job := range wf.Jobs {
+			if job == nil {
+				continue
+			}
+			for _, name := range sortedWorkflowJobNames(job) {
+				ref := &JobReference{Workflow: wfName, Index: i, Name: name}
+				resolveJobReference(cfg, ref, job.AdditionalProperties[name])
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// resolveJobReference sets the Kind of ref, and what it refers to, for the workflow job item.
+func resolveJobReference(cfg *CircleCIConfigSchema, ref *JobReference, item *WorkflowJobSchemaItem) {
+	if item != nil && item.JobType == "approval" {
+		ref.Kind = JobRefApproval
+		return
+	}
+	if isParameterExpression(ref.Name) {
+		ref.Kind = JobRefParameter
+		return
+	}
+
+	alias, jobName, ok := cut(ref.Name, "/")
+	if !ok {
+		if cfg.Jobs != nil {
+			if job, ok := cfg.Jobs.AdditionalProperties[ref.Name]; ok {
+				ref.Kind = JobRefDefined
+				ref.Job = job
+				return
+			}
+		}
+		ref.Kind = JobRefUnresolved
+		ref.Err = &InvalidValueError{Value: ref.Name, Reason: "no such job", Suggestion: suggest(ref.Name, jobReferenceNames(cfg))}
+		return
+	}
+
+	var orb *ConfigOrbImport
+	if cfg.Orbs != nil {
+		orb = cfg.Orbs.AdditionalProperties[alias]
+	}
+	if orb == nil {
+		ref.Kind = JobRefUnresolved
+		ref.Err = &InvalidValueError{Value: ref.Name, Reason: fmt.Sprintf("no such orb %q", alias), Suggestion: suggest(ref.Name, jobReferenceNames(cfg))}
+		return
+	}
+	if orb.Inline != nil {
+		var job *JobSchemaItem
+		if orb.Inline.Jobs != nil {
+			job = orb.Inline.Jobs.AdditionalProperties[jobName]
+		}
+		if job == nil {
+			ref.Kind = JobRefUnresolved
+			ref.Err = &InvalidValueError{Value: ref.Name, Reason: fmt.Sprintf("no such job in orb %q", alias), Suggestion: suggest(ref.Name, jobReferenceNames(cfg))}
+			return
+		}
+		ref.Job = job
+	}
+	ref.Kind = JobRefOrb
+	ref.Orb = alias
+}
+
+// jobReferenceNames returns the names a workflow job may refer to a job by: the jobs of cfg, and the jobs of its inline orbs.
+func jobReferenceNames(cfg *CircleCIConfigSchema) []string {
+	var names []string
+	if cfg.Jobs != nil {
+		names = append(names, sortedJobNames(cfg.Jobs)...)
+	}
+	if cfg.Orbs != nil {
+		for _, alias := range sortedOrbAliases(cfg.Orbs) {
+			orb := cfg.Orbs.AdditionalProperties[alias]
+			if orb == nil || orb.Inline == nil || orb.Inline.Jobs == nil {
+				continue
+			}
+			for _, name := range sortedJobNames(orb.Inline.Jobs) {
+				names = append(names, alias+"/"+name)
+			}
+		}
+	}
+	return names
+}
+
+// checkJobReferences reports workflow jobs that do not refer to a job of the config, of one of its orbs, or to an approval job,
+// and approval jobs named after a job of the config.
+func checkJobReferences(cfg *CircleCIConfigSchema, report *Report) {
+	for _, ref := range ResolveJobReferences(cfg) {
+		ptr := jsonPointer("workflows", ref.Workflow, "jobs", strconv.Itoa(ref.Index), ref.Name)
+		switch ref.Kind {
+		case JobRefUnresolved:
+			report.add(SeverityError, locate(ptr, ref.Err))
+		case JobRefApproval:
+			if cfg.Jobs != nil && cfg.Jobs.AdditionalProperties[ref.Name] != nil {
+				report.add(SeverityError, &InvalidValueError{
+					Path:   ptr,
+					Value:  ref.Name,
+					Reason: "approval job has the name of a job defined under jobs; give it a distinct name",
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"testing"
+)
+
+func TestResolveJobReferences(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodeConfig(t, `
+version: 2.1
+orbs:
+  node: circleci/node@4.7
+  local:
+    jobs:
+      lint: {docker: [{image: a}], steps: [checkout]}
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - build
+      - hold: {type: approval}
+      - node/test
+      - local/lint
+      - << pipeline.parameters.job >>
+      - local/vet
+      - go/test
+      - biuld
+`)
+	want := []struct {
+		name, kind, orb string
+		job             bool
+	}{
+		{name: "build", kind: JobRefDefined, job: true},
+		{name: "hold", kind: JobRefApproval},
+		{name: "node/test", kind: JobRefOrb, orb: "node"},
+		{name: "local/lint", kind: JobRefOrb, orb: "local", job: true},
+		{name: "<< pipeline.parameters.job >>", kind: JobRefParameter},
+		{name: "local/vet", kind: JobRefUnresolved},
+		{name: "go/test", kind: JobRefUnresolved},
+		{name: "biuld", kind: JobRefUnresolved},
+	}
+
+	refs := ResolveJobReferences(cfg)
+	if len(refs) != len(want) {
+		t.Fatalf("ResolveJobReferences() returned %d jobs, want %d", len(refs), len(want))
+	}
+	for i, ref := range refs {
+		w := want[i]
+		if ref.Workflow != "main" || ref.Index != i || ref.Name != w.name || ref.Kind != w.kind || ref.Orb != w.orb || (ref.Job != nil) != w.job {
+			t.Errorf("ResolveJobReferences()[%d] = %+v, want %+v", i, ref, w)
+		}
+		if (ref.Err != nil) != (w.kind == JobRefUnresolved) {
+			t.Errorf("ResolveJobReferences()[%d].Err = %v", i, ref.Err)
+		}
+	}
+
+	if refs := ResolveJobReferences(&CircleCIConfigSchema{}); refs != nil {
+		t.Errorf("ResolveJobReferences() without workflows = %v, want nil", refs)
+	}
+}
+
+func TestValidateJobReferences(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "valid",
+			config: `
+version: 2.1
+orbs:
+  node: circleci/node@4.7
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - build
+      - hold: {type: approval, requires: [build]}
+      - node/test: {requires: [hold]}
+`,
+		},
+		{
+			name: "unresolved",
+			config: `
+version: 2.1
+orbs:
+  local:
+    jobs:
+      lint: {docker: [{image: a}], steps: [checkout]}
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - biuld
+      - local/lnt
+      - go/test
+`,
+			want: []string{
+				`12:9: error: /workflows/main/jobs/0/biuld: invalid value "biuld": no such job, did you mean "build"?`,
+				`13:9: error: /workflows/main/jobs/1/local~1lnt: invalid value "local/lnt": no such job in orb "local", did you mean "local/lint"?`,
+				`14:9: error: /workflows/main/jobs/2/go~1test: invalid value "go/test": no such orb "go"`,
+			},
+		},
+		{
+			name: "approval job named like a defined job",
+			config: `
+version: 2.1
+jobs:
+  build: {docker: [{image: a}], steps: [checkout]}
+workflows:
+  main:
+    jobs:
+      - build: {type: approval}
+`,
+			want: []string{
+				`8:9: error: /workflows/main/jobs/0/build: invalid value "build": approval job has the name of a job defined under jobs; give it a distinct name`,
+			},
+		},
+	})
+}
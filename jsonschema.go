@@ -0,0 +1,460 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	_ "embed" // for go:embed
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// The embedded JSON Schema is maintained with this package, in schema/circleci-config.json. It is not CircleCI's
+// official schema, nor derived from it: it describes the configs this package decodes, and checks the constraints its
+// types cannot express.
+
+// JSONSchemaRevision the revision of the embedded JSON Schema. It is incremented whenever the schema changes.
+const JSONSchemaRevision = 1
+
+// jsonSchemaURL the URL the embedded JSON Schema is registered under. It is never fetched.
+const jsonSchemaURL = "https://github.com/zchee/circleci-validator/schema/circleci-config.json"
+
+//go:embed schema/circleci-config.json
+var jsonSchemaSource []byte
+
+var (
+	compileJSONSchemaOnce sync.Once
+	compiledJSONSchema    *jsonschema.Schema
+	jsonSchemaDocument    interface{}
+)
+
+// JSONSchema returns the embedded JSON Schema (draft-07) of CircleCI configs maintained with this package, for use with other tools.
+// It is not CircleCI's official schema.
+func JSONSchema() []byte {
+	return append([]byte(nil), jsonSchemaSource...)
+}
+
+// compileJSONSchema returns the compiled embedded JSON Schema, and the schema itself decoded as a JSON value.
+func compileJSONSchema() (*jsonschema.Schema, interface{}) {
+	compileJSONSchemaOnce.Do(func() {
+		c := jsonschema.NewCompiler()
+		c.Draft = jsonschema.Draft7
+		if err := c.AddResource(jsonSchemaURL, bytes.NewReader(jsonSchemaSource)); err != nil {
+			panic(fmt.Sprintf("ccivalidator: invalid embedded JSON Schema: %v", err))
+		}
+		compiledJSONSchema = c.MustCompile(jsonSchemaURL)
+		if err := json.Unmarshal(jsonSchemaSource, &jsonSchemaDocument); err != nil {
+			panic(fmt.Sprintf("ccivalidator: invalid embedded JSON Schema: %v", err))
+		}
+	})
+	return compiledJSONSchema, jsonSchemaDocument
+}
+
+// ValidateSchema validates the source of doc against the embedded JSON Schema.
+//
+// Unlike decoding, which only checks what the types in schema.go can express, this checks every constraint of the schema,
+// such as enums, patterns and the alternative forms of a step. If doc was not produced by a Decoder, its Config is validated instead.
+//
+// The returned error is nil or an Errors holding one *MissingFieldError, *UnknownFieldError, *TypeMismatchError or *InvalidValueError per violation,
+// located by the JSON pointer of the offending node in the config.
+func ValidateSchema(doc *Document) error {
+	src := doc.source
+	if src == nil {
+		b, err := json.Marshal(doc.Config)
+		if err != nil {
+			return err
+		}
+		src = b
+	}
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	sch, schemaDoc := compileJSONSchema()
+	err := sch.Validate(v)
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+
+	c := &schemaViolations{instance: v, schema: schemaDoc}
+	c.collect(ve)
+	return c.errs.err()
+}
+
+// schemaViolations converts a *jsonschema.ValidationError tree into the errors of this package.
+type schemaViolations struct {
+	instance interface{}
+	schema   interface{}
+	errs     Errors
+}
+
+// collect adds an error for each leaf of the tree rooted at ve.
+//
+// When none of the alternatives of a oneOf or anyOf matches, only the alternative that got furthest is reported, rather than every one of them.
+// If no alternative accepts the type of the value, a single *TypeMismatchError listing the accepted types is reported instead.
+func (c *schemaViolations) collect(ve *jsonschema.ValidationError) {
+	if len(ve.Causes) == 0 {
+		c.leaf(ve)
+		return
+	}
+
+	switch keyword(ve.KeywordLocation) {
+	case "oneOf", "anyOf":
+		var best *jsonschema.ValidationError
+		bestDepth := -1
+		var expected []string
+		for _, cause := range ve.Causes {
+			if types, ok := c.typeMismatch(cause, ve.InstanceLocation); ok {
+				expected = appendUnique(expected, types...)
+				continue
+			}
+			if d := maxInstanceDepth(cause); d > bestDepth {
+				best, bestDepth = cause, d
+			}
+		}
+		if best == nil {
+			c.errs.add(&TypeMismatchError{
+				Path:     ve.InstanceLocation,
+				Expected: strings.Join(expected, " or "),
+				Actual:   jsonValueType(c.value(ve.InstanceLocation)),
+			})
+			return
+		}
+		if bestDepth == pointerDepth(ve.InstanceLocation) {
+			if fields, ok := missingOnly(ve, ve.InstanceLocation); ok {
+				c.errs.add(&InvalidValueError{
+					Path:   ve.InstanceLocation,
+					Reason: fmt.Sprintf("one of %s is required", strings.Join(fields, ", ")),
+				})
+				return
+			}
+		}
+		c.collect(best)
+	default:
+		for _, cause := range ve.Causes {
+			c.collect(cause)
+		}
+	}
+}
+
+// typeMismatch reports whether the alternative ve of a oneOf or anyOf fails because it does not accept the type of the value at loc,
+// and if so returns the types it accepts.
+//
+// An alternative that only accepts parameter expressions, such as "<< parameters.name >>", counts as a type mismatch that accepts no type,
+// so that a misspelled boolean is reported as such rather than as a malformed expression.
+func (c *schemaViolations) typeMismatch(ve *jsonschema.ValidationError, loc string) ([]string, bool) {
+	var types []string
+	mismatch, onlyExpr := false, true
+	walkLeaves(ve, func(leaf *jsonschema.ValidationError) {
+		if strings.Contains(leaf.AbsoluteKeywordLocation, "/definitions/parameterExpression/") {
+			return
+		}
+		onlyExpr = false
+		if leaf.InstanceLocation == loc && keyword(leaf.KeywordLocation) == "type" {
+			mismatch = true
+			if i := strings.Index(leaf.Message, ", but got "); i >= 0 {
+				types = appendUnique(types, strings.Split(strings.TrimPrefix(leaf.Message[:i], "expected "), " or ")...)
+			}
+		}
+	})
+	return types, mismatch || onlyExpr
+}
+
+// missingOnly reports whether every leaf of ve is a missing "required" property at loc, and if so returns the quoted names of those properties.
+func missingOnly(ve *jsonschema.ValidationError, loc string) ([]string, bool) {
+	var fields []string
+	ok := true
+	walkLeaves(ve, func(leaf *jsonschema.ValidationError) {
+		if leaf.InstanceLocation != loc || keyword(leaf.KeywordLocation) != "required" {
+			ok = false
+			return
+		}
+		for _, f := range quotedNames(leaf.Message) {
+			fields = appendUnique(fields, strconv.Quote(f))
+		}
+	})
+	return fields, ok
+}
+
+// leaf adds the error for a single violation.
+func (c *schemaViolations) leaf(ve *jsonschema.ValidationError) {
+	loc := ve.InstanceLocation
+	switch keyword(ve.KeywordLocation) {
+	case "required":
+		for _, f := range quotedNames(ve.Message) {
+			c.errs.add(&MissingFieldError{Path: loc + jsonPointer(f), Field: f})
+		}
+		return
+	case "additionalProperties":
+		known := c.knownProperties(ve.AbsoluteKeywordLocation)
+		for _, f := range quotedNames(ve.Message) {
+			c.errs.add(&UnknownFieldError{
+				Path:       loc + jsonPointer(f),
+				Field:      f,
+				Suggestion: suggest(f, known),
+			})
+		}
+		return
+	case "type":
+		if i := strings.Index(ve.Message, ", but got "); i >= 0 {
+			c.errs.add(&TypeMismatchError{
+				Path:     loc,
+				Expected: strings.TrimPrefix(ve.Message[:i], "expected "),
+				Actual:   jsonValueType(c.value(loc)),
+			})
+			return
+		}
+	}
+
+	err := &InvalidValueError{Path: loc}
+	switch v := c.value(loc).(type) {
+	case string, bool, json.Number:
+		err.Value = v
+	}
+	switch keyword(ve.KeywordLocation) {
+	case "enum":
+		err.Reason, err.Suggestion = c.enum(ve, err.Value)
+	case "oneOf":
+		err.Reason = c.ambiguous(ve)
+	default:
+		err.Reason = c.reason(ve)
+	}
+	c.errs.add(err)
+}
+
+// reason returns the reason for a violation of any other keyword, worded like the errors of decoding rather than the messages of the jsonschema package.
+func (c *schemaViolations) reason(ve *jsonschema.ValidationError) string {
+	kw := keyword(ve.KeywordLocation)
+	loc := ve.AbsoluteKeywordLocation
+	limit := pointerValue(c.schema, loc[strings.IndexByte(loc, '#')+1:])
+	switch kw {
+	case "const":
+		return "must be " + formatValue(limit)
+	case "minProperties":
+		return fmt.Sprintf("must have at least %s", plural(limit, "field"))
+	case "maxProperties":
+		return fmt.Sprintf("must have at most %s", plural(limit, "field"))
+	case "minItems":
+		return fmt.Sprintf("must hold at least %s", plural(limit, "value"))
+	case "maxItems":
+		return fmt.Sprintf("must hold at most %s", plural(limit, "value"))
+	case "uniqueItems":
+		return "must not hold the same value twice"
+	case "minLength":
+		return fmt.Sprintf("must be at least %s long", plural(limit, "character"))
+	case "maxLength":
+		return fmt.Sprintf("must be at most %s long", plural(limit, "character"))
+	case "minimum":
+		return fmt.Sprintf("must be at least %v", limit)
+	case "maximum":
+		return fmt.Sprintf("must be at most %v", limit)
+	case "exclusiveMinimum":
+		return fmt.Sprintf("must be greater than %v", limit)
+	case "exclusiveMaximum":
+		return fmt.Sprintf("must be less than %v", limit)
+	case "multipleOf":
+		return fmt.Sprintf("must be a multiple of %v", limit)
+	case "pattern":
+		if strings.Contains(ve.KeywordLocation, "/propertyNames/") {
+			return fmt.Sprintf("field names must match %q", limit)
+		}
+		return fmt.Sprintf("must match %q", limit)
+	case "format":
+		return fmt.Sprintf("not a valid %v", limit)
+	}
+	return "not allowed here"
+}
+
+// plural returns n followed by noun, in the plural unless n is 1, e.g. "2 fields".
+func plural(n interface{}, noun string) string {
+	if fmt.Sprint(n) == "1" {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%v %ss", n, noun)
+}
+
+// enum returns the reason for a value that is not one of the values allowed by an enum, and the allowed value closest to v.
+func (c *schemaViolations) enum(ve *jsonschema.ValidationError, v interface{}) (reason, suggestion string) {
+	loc := ve.AbsoluteKeywordLocation
+	values, _ := pointerValue(c.schema, loc[strings.IndexByte(loc, '#')+1:]).([]interface{})
+	if len(values) == 0 {
+		return ve.Message, ""
+	}
+	allowed := make([]string, len(values))
+	var names []string
+	for i, value := range values {
+		allowed[i] = formatValue(value)
+		if name, ok := value.(string); ok {
+			names = append(names, name)
+		}
+	}
+	if s, ok := v.(string); ok {
+		suggestion = suggest(s, names)
+	}
+	return "must be one of " + strings.Join(allowed, ", "), suggestion
+}
+
+// ambiguous returns the reason for a value that matches more than one alternative of a oneOf.
+// If the alternatives are told apart by a required field, such as "docker" and "machine" for the executor of a job, the reason names those fields.
+func (c *schemaViolations) ambiguous(ve *jsonschema.ValidationError) string {
+	const reason = "matches more than one of the allowed forms"
+	var i, j int
+	if _, err := fmt.Sscanf(ve.Message, "valid against schemas at indexes %d and %d", &i, &j); err != nil {
+		return reason
+	}
+	loc := ve.AbsoluteKeywordLocation
+	ptr := loc[strings.IndexByte(loc, '#')+1:]
+	var fields []string
+	for _, k := range []int{i, j} {
+		required, _ := pointerValue(c.schema, ptr+"/"+strconv.Itoa(k)+"/required").([]interface{})
+		if len(required) != 1 {
+			return reason
+		}
+		fields = append(fields, strconv.Quote(fmt.Sprint(required[0])))
+	}
+	return fmt.Sprintf("%s and %s cannot be used together", fields[0], fields[1])
+}
+
+// value returns the value of the instance at the JSON pointer ptr, or nil if there is none.
+func (c *schemaViolations) value(ptr string) interface{} {
+	return pointerValue(c.instance, ptr)
+}
+
+// knownProperties returns the names of the properties declared next to the additionalProperties keyword at the absolute keyword location loc.
+func (c *schemaViolations) knownProperties(loc string) []string {
+	i := strings.IndexByte(loc, '#')
+	if i < 0 {
+		return nil
+	}
+	ptr := strings.TrimSuffix(loc[i+1:], "/additionalProperties")
+	props, _ := pointerValue(c.schema, ptr+"/properties").(map[string]interface{})
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	return names
+}
+
+// walkLeaves calls fn for each leaf of the tree rooted at ve.
+func walkLeaves(ve *jsonschema.ValidationError, fn func(*jsonschema.ValidationError)) {
+	if len(ve.Causes) == 0 {
+		fn(ve)
+		return
+	}
+	for _, cause := range ve.Causes {
+		walkLeaves(cause, fn)
+	}
+}
+
+// maxInstanceDepth returns the depth of the deepest instance location among the leaves of ve.
+func maxInstanceDepth(ve *jsonschema.ValidationError) int {
+	depth := 0
+	walkLeaves(ve, func(leaf *jsonschema.ValidationError) {
+		if d := pointerDepth(leaf.InstanceLocation); d > depth {
+			depth = d
+		}
+	})
+	return depth
+}
+
+func pointerDepth(ptr string) int {
+	return strings.Count(ptr, "/")
+}
+
+// keyword returns the last segment of the keyword location loc, e.g. "type" for "/properties/jobs/additionalProperties/type".
+func keyword(loc string) string {
+	return loc[strings.LastIndexByte(loc, '/')+1:]
+}
+
+// quotedNames returns the single-quoted names in msg, as written by the jsonschema package, e.g. "a" and "b" for "missing properties: 'a', 'b'".
+func quotedNames(msg string) []string {
+	var names []string
+	for {
+		i := strings.IndexByte(msg, '\'')
+		if i < 0 {
+			return names
+		}
+		msg = msg[i+1:]
+		var sb strings.Builder
+		for len(msg) > 0 && msg[0] != '\'' {
+			if msg[0] == '\\' && len(msg) > 1 {
+				msg = msg[1:]
+			}
+			sb.WriteByte(msg[0])
+			msg = msg[1:]
+		}
+		if len(msg) == 0 {
+			return names
+		}
+		msg = msg[1:]
+		names = append(names, sb.String())
+	}
+}
+
+// pointerValue returns the value at the JSON pointer ptr within the decoded JSON value v, or nil if there is none.
+func pointerValue(v interface{}, ptr string) interface{} {
+	if ptr == "" {
+		return v
+	}
+	for _, seg := range strings.Split(ptr[1:], "/") {
+		seg = pointerUnescaper.Replace(seg)
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v = t[seg]
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil
+			}
+			v = t[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// jsonValueType returns the name of the JSON type of the decoded JSON value v.
+// Like encoding/json, it names every number "number", whether or not it is an integer.
+func jsonValueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "value"
+}
+
+func appendUnique(s []string, elems ...string) []string {
+outer:
+	for _, e := range elems {
+		for _, x := range s {
+			if x == e {
+				continue outer
+			}
+		}
+		s = append(s, e)
+	}
+	return s
+}
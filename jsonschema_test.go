@@ -0,0 +1,281 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	runValidateTests(t, &Validator{JSONSchema: true}, []validateTest{
+		{
+			name:   "valid",
+			config: validConfig,
+		},
+		{
+			name: "constraint the types do not express",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - run: {command: make, when: sometimes}
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`7:30: error: /jobs/build/steps/0/run/when: invalid value "sometimes": must be one of "always", "on_success", "on_fail"`,
+			},
+		},
+		{
+			name: "reported once when decoding reports it too",
+			config: `
+version: 2.1
+orbs:
+  node: circleci/node
+jobs:
+  build:
+    docker: [{image: 5}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`4:3: error: /orbs/node: invalid value "circleci/node": missing version, as in "circleci/node@5.0.2"`,
+				`7:15: error: /jobs/build/docker/0/image: expected string but got number`,
+			},
+		},
+	})
+}
+
+func TestValidateSchemaMessages(t *testing.T) {
+	t.Parallel()
+
+	doc, err := NewDecoder(strings.NewReader(`
+version: 2.1
+jobs:
+  build:
+    docker: []
+    resource_class: 2
+    steps: [{}]
+`)).Decode()
+	if doc == nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	err = ValidateSchema(doc)
+	var got []string
+	for _, err := range err.(Errors) {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		`/jobs/build/docker: must hold at least 1 value`,
+		`/jobs/build/resource_class: expected string but got number`,
+		`/jobs/build/steps/0: must have at least 1 field`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ValidateSchema() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateSchemaErrorTypes(t *testing.T) {
+	t.Parallel()
+
+	doc, err := NewDecoder(strings.NewReader(`
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - run: {command: make, when: sometimes}
+workflows:
+  main:
+    jobs: [build]
+`)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	err = ValidateSchema(doc)
+	var invalid *InvalidValueError
+	if !errors.As(err, &invalid) || invalid.Path != "/jobs/build/steps/0/run/when" || invalid.Value != "sometimes" {
+		t.Errorf("ValidateSchema() error = %v, want an *InvalidValueError at /jobs/build/steps/0/run/when", err)
+	}
+}
+
+func TestJSONSchemaAgreesWithDecoding(t *testing.T) {
+	runValidateTests(t, &Validator{DisallowUnknownFields: true, JSONSchema: true}, []validateTest{
+		{
+			name: "every key the schema declares",
+			config: `
+version: 2.1
+setup: false
+orbs:
+  node: circleci/node@5.0.2
+  local:
+    version: 2.1
+    description: An inline orb.
+    display: {home_url: "https://example.com", source_url: "https://example.com/src"}
+    examples: {}
+    orbs: {go: circleci/go@1.7.1}
+    commands:
+      hello: {steps: [checkout]}
+    executors:
+      default: {docker: [{image: a}]}
+    jobs:
+      lint: {docker: [{image: a}], steps: [checkout]}
+parameters:
+  deploy: {type: boolean, default: false, description: Whether to deploy.}
+  env: {type: enum, enum: [dev, prod], default: dev}
+commands:
+  greet:
+    description: Greets.
+    parameters:
+      to: {type: string, default: world, description: Who to greet.}
+    steps:
+      - run: echo hello << parameters.to >>
+executors:
+  go:
+    description: A Go image.
+    parameters:
+      tag: {type: string, default: "1.17"}
+    docker:
+      - image: cimg/go:<< parameters.tag >>
+        name: go
+        user: circleci
+        entrypoint: [/bin/sh]
+        command: [-c]
+        environment: {A: b}
+        auth: {username: u, password: $P}
+      - image: 1234.dkr.ecr.us-east-1.amazonaws.com/db
+        aws_auth: {aws_access_key_id: a, aws_secret_access_key: b}
+      - image: 1234.dkr.ecr.us-east-1.amazonaws.com/cache
+        aws_auth: {oidc_role_arn: "arn:aws:iam::1234:role/ci"}
+    resource_class: large
+    shell: /bin/bash
+    working_directory: ~/src
+    environment: {B: c}
+  vm:
+    machine: {image: ubuntu-2004:202010-01, docker_layer_caching: true}
+    resource_class: large
+  mac:
+    macos: {xcode: 13.0.0}
+jobs:
+  build:
+    description: Builds.
+    executor: {name: go, tag: "1.16"}
+    parameters:
+      race: {type: boolean, default: true}
+    parallelism: 2
+    circleci_ip_ranges: true
+    environment: {C: d}
+    shell: /bin/bash
+    working_directory: ~/src
+    resource_class: medium
+    steps:
+      - checkout: {name: Checkout, path: src, method: blobless}
+      - run:
+          name: Test
+          command: go test ./...
+          shell: /bin/bash
+          environment: {D: e}
+          background: false
+          working_directory: src
+          no_output_timeout: 10m
+          when: always
+          max_auto_reruns: 3
+          auto_rerun_delay: 10s
+      - setup_remote_docker: {name: Docker, version: 20.10.7, docker_layer_caching: true}
+      - save_cache: {name: Save, key: v1, paths: [a], when: on_success}
+      - restore_cache: {name: Restore, keys: [v1]}
+      - restore_cache: {key: v1}
+      - store_artifacts: {name: Artifacts, path: a, destination: b}
+      - store_test_results: {name: Results, path: r}
+      - persist_to_workspace: {name: Persist, root: ., paths: [a]}
+      - attach_workspace: {name: Attach, at: .}
+      - add_ssh_keys: {name: Keys, fingerprints: ["a:b"]}
+      - deploy: {command: make deploy}
+      - when:
+          condition:
+            and:
+              - or: [true, false]
+              - not: false
+              - equal: [main, << pipeline.git.branch >>]
+              - matches: {pattern: "^main$", value: << pipeline.git.branch >>}
+          steps: [checkout]
+      - unless: {condition: false, steps: [checkout]}
+      - steps: [checkout]
+      - greet: {to: you}
+  vm:
+    machine: true
+    branches: {only: [main], ignore: [dev]}
+    steps: [checkout]
+  mac:
+    macos: {xcode: 13.0.0}
+    steps: [checkout]
+  run-on:
+    executor: vm
+    steps: [checkout]
+workflows:
+  version: 2
+  main:
+    when: << pipeline.parameters.deploy >>
+    jobs:
+      - build:
+          name: build-all
+          context: [org]
+          race: false
+          requires: []
+          type: no-op
+          pre-steps: [checkout]
+          post-steps: [checkout]
+          filters:
+            branches: {only: [main], ignore: [dev]}
+            tags: {only: [/v.*/], ignore: [/x.*/]}
+          matrix:
+            alias: builds
+            parameters: {race: [true, false]}
+            exclude: [{race: false}]
+      - vm
+      - mac
+      - run-on
+      - local/lint
+  nightly:
+    unless: false
+    triggers:
+      - schedule:
+          cron: "0 3 * * *"
+          filters: {branches: {only: [main]}}
+    jobs: [vm]
+`,
+		},
+		{
+			name: "keys the schema and the types both reject",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker:
+      - image: 1234.dkr.ecr.us-east-1.amazonaws.com/db
+        aws_auth: {aws_access_key_id: a, oidc_role_arn: b}
+    steps:
+      - run: {command: make, max_auto_reruns: 6}
+  test:
+    machine: {image: ubuntu-2004:202010-01, shell: /bin/bash}
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build, test]
+`,
+			want: []string{
+				`7:9: error: /jobs/build/docker/0/aws_auth: "aws_access_key_id" and "oidc_role_arn" cannot be used together`,
+				`9:30: error: /jobs/build/steps/0/run/max_auto_reruns: invalid value 6: must be at most 5`,
+				`11:45: error: /jobs/test/machine: unknown field "shell"`,
+			},
+		},
+	})
+}
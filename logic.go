@@ -0,0 +1,509 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LogicSchema json schema for a logic statement, the condition of a when or unless step, or of a workflow.
+//
+// A statement is either a literal value, or an object with a single key naming its operator: "and", "or", "not", "equal"
+// or "matches". A literal holds unless it is false, null, 0, the empty string or NaN, once its parameter expressions are
+// resolved. Exactly one of And, Or, Not, Equal and Matches is set for an operator; none is set for a literal.
+type LogicSchema struct {
+	// Literal the value of a literal statement, such as true or "<< pipeline.parameters.deploy >>".
+	Literal interface{} `json:"-"`
+
+	// And holds if all of its statements hold.
+	And []*LogicSchema `json:"and,omitempty"`
+
+	// Or holds if any of its statements holds.
+	Or []*LogicSchema `json:"or,omitempty"`
+
+	// Not holds if its statement does not hold.
+	Not *LogicSchema `json:"not,omitempty"`
+
+	// Equal holds if all of its values are equal.
+	Equal []interface{} `json:"equal,omitempty"`
+
+	// Matches holds if its value matches its pattern.
+	Matches *LogicMatchesSchema `json:"matches,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+// logicOperators the keys of a LogicSchema object.
+var logicOperators = []string{"and", "equal", "matches", "not", "or"}
+
+// IsLiteral reports whether r is a literal statement rather than an operator.
+func (r *LogicSchema) IsLiteral() bool {
+	return r.And == nil && r.Or == nil && r.Not == nil && r.Equal == nil && r.Matches == nil
+}
+
+func (r *LogicSchema) MarshalJSON() ([]byte, error) {
+	if r.IsLiteral() {
+		return json.Marshal(r.Literal)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "and" field
+	if r.And != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"and\": ")
+		if tmp, err := json.Marshal(r.And); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "or" field
+	if r.Or != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"or\": ")
+		if tmp, err := json.Marshal(r.Or); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "not" field
+	if r.Not != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"not\": ")
+		if tmp, err := json.Marshal(r.Not); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "equal" field
+	if r.Equal != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"equal\": ")
+		if tmp, err := json.Marshal(r.Equal); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "matches" field
+	if r.Matches != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"matches\": ")
+		if tmp, err := json.Marshal(r.Matches); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *LogicSchema) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v.(type) {
+	case map[string]interface{}:
+	case []interface{}:
+		return &TypeMismatchError{Expected: "logic statement", Actual: "array"}
+	default:
+		r.Literal = v
+		return nil
+	}
+
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	if len(jsonMap) != 1 {
+		operators := make([]string, len(logicOperators))
+		for i, op := range logicOperators {
+			operators[i] = strconv.Quote(op)
+		}
+		return &InvalidValueError{Reason: fmt.Sprintf("a logic statement must have exactly one operator, one of %s", joinList(operators))}
+	}
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "and":
+			if err := unmarshalValue(v, &r.And); err != nil {
+				errs.addAt(k, err)
+			}
+			if r.And == nil {
+				r.And = []*LogicSchema{}
+			}
+		case "or":
+			if err := unmarshalValue(v, &r.Or); err != nil {
+				errs.addAt(k, err)
+			}
+			if r.Or == nil {
+				r.Or = []*LogicSchema{}
+			}
+		case "not":
+			r.Not = new(LogicSchema)
+			if err := unmarshalValue(v, r.Not); err != nil {
+				errs.addAt(k, err)
+			}
+		case "equal":
+			if err := unmarshalValue(v, &r.Equal); err != nil {
+				errs.addAt(k, err)
+			}
+			if len(r.Equal) == 0 {
+				errs.addAt(k, &InvalidValueError{Reason: "must hold at least one value"})
+				r.Equal = []interface{}{}
+			}
+		case "matches":
+			if err := unmarshalValue(v, &r.Matches); err != nil {
+				errs.addAt(k, err)
+			}
+			if r.Matches == nil {
+				r.Matches = new(LogicMatchesSchema)
+			}
+		default:
+			// an unknown operator leaves the statement without a meaning, so unlike an unknown field it is always reported
+			errs.add(&InvalidValueError{Path: jsonPointer(k), Value: k, Reason: "unknown operator", Suggestion: suggest(k, logicOperators)})
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *LogicSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *LogicSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// LogicMatchesSchema json schema for the matches operator of a logic statement.
+type LogicMatchesSchema struct {
+	// The regular expression the whole value must match.
+	Pattern string `json:"pattern"`
+
+	// The value matched, typically a parameter expression such as "<< pipeline.git.branch >>".
+	Value string `json:"value"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *LogicMatchesSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Pattern" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "pattern" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"pattern\": ")
+	if tmp, err := json.Marshal(r.Pattern); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// "Value" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "value" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"value\": ")
+	if tmp, err := json.Marshal(r.Value); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *LogicMatchesSchema) UnmarshalJSON(b []byte) error {
+	patternReceived := false
+	valueReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "pattern":
+			if err := unmarshalValue(v, &r.Pattern); err != nil {
+				errs.addAt(k, err)
+			} else if _, err := regexp.Compile(r.Pattern); err != nil && !isParameterExpression(r.Pattern) {
+				errs.addAt(k, &InvalidValueError{Value: r.Pattern, Reason: "invalid regular expression: " + regexpErrorReason(err)})
+			}
+			patternReceived = true
+		case "value":
+			if err := unmarshalValue(v, &r.Value); err != nil {
+				errs.addAt(k, err)
+			}
+			valueReceived = true
+		default:
+			errs.add(unknownField(k, "pattern", "value"))
+		}
+	}
+	// check if pattern (a required property) was received
+	if !patternReceived {
+		errs.add(missingField("pattern"))
+	}
+	// check if value (a required property) was received
+	if !valueReceived {
+		errs.add(missingField("value"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *LogicMatchesSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *LogicMatchesSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// compileMatchesPattern compiles the pattern of a matches operator, which like Java's String.matches must match the whole value.
+func compileMatchesPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// regexpErrorReason returns the message of the regexp compilation error err, without its "error parsing regexp: " prefix.
+func regexpErrorReason(err error) string {
+	return strings.TrimPrefix(err.Error(), "error parsing regexp: ")
+}
+
+// LogicContext the values the parameter expressions of a logic statement are resolved against.
+type LogicContext struct {
+	// Pipeline the values of "<< pipeline.id >>", "<< pipeline.number >>" and "<< pipeline.parameters.name >>".
+	Pipeline *Pipeline
+
+	// Git the values of "<< pipeline.git.branch >>", "<< pipeline.git.tag >>", "<< pipeline.git.revision >>" and
+	// "<< pipeline.git.base_revision >>".
+	Git *Git
+
+	// Project the values of "<< pipeline.project.git_url >>" and "<< pipeline.project.type >>".
+	Project *Project
+
+	// Parameters the values of "<< parameters.name >>", the parameters of the job or command the statement is part of.
+	Parameters map[string]interface{}
+}
+
+// errUnresolved the error wrapped by Evaluate when a parameter expression refers to a value the LogicContext does not hold.
+var errUnresolved = errors.New("unresolved parameter expression")
+
+// parameterExpressionRe matches a single parameter expression, capturing its reference such as "pipeline.git.branch".
+var parameterExpressionRe = regexp.MustCompile(`<<\s*([^<>]+?)\s*>>`)
+
+// Evaluate reports whether r holds in ctx.
+//
+// Parameter expressions are resolved against ctx. A literal that is a single expression, as in "<< pipeline.parameters.deploy >>",
+// takes the value of the expression as is, so that a boolean parameter holds only if it is true.
+// The returned error is non-nil if an expression cannot be resolved, or the pattern of a matches operator does not compile.
+func (r *LogicSchema) Evaluate(ctx *LogicContext) (bool, error) {
+	switch {
+	case r == nil:
+		// a null statement, as in "and: [null]"
+		return false, nil
+	case r.And != nil:
+		for _, s := range r.And {
+			if ok, err := s.Evaluate(ctx); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case r.Or != nil:
+		for _, s := range r.Or {
+			if ok, err := s.Evaluate(ctx); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case r.Not != nil:
+		ok, err := r.Not.Evaluate(ctx)
+		return !ok && err == nil, err
+	case r.Equal != nil:
+		var first interface{}
+		for i, v := range r.Equal {
+			resolved, err := ctx.resolve(v)
+			if err != nil {
+				return false, err
+			}
+			if i == 0 {
+				first = resolved
+				continue
+			}
+			if !reflect.DeepEqual(first, resolved) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case r.Matches != nil:
+		re, err := compileMatchesPattern(r.Matches.Pattern)
+		if err != nil {
+			return false, err
+		}
+		value, err := ctx.resolve(r.Matches.Value)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(fmt.Sprint(value)), nil
+	}
+	v, err := ctx.resolve(r.Literal)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// truthy reports whether the resolved value v of a literal holds.
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// resolve substitutes the parameter expressions of the decoded JSON value v.
+//
+// A string that is a single expression takes the value of the expression; numbers are returned as float64, as decoded from JSON.
+func (ctx *LogicContext) resolve(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	if m := parameterExpressionRe.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		return ctx.lookup(s[m[2]:m[3]])
+	}
+
+	var firstErr error
+	resolved := parameterExpressionRe.ReplaceAllStringFunc(s, func(expr string) string {
+		ref := parameterExpressionRe.FindStringSubmatch(expr)[1]
+		v, err := ctx.lookup(ref)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return expr
+		}
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	})
+	return resolved, firstErr
+}
+
+// lookup returns the value ref, such as "pipeline.git.branch", refers to.
+func (ctx *LogicContext) lookup(ref string) (interface{}, error) {
+	unresolved := fmt.Errorf("%w %q", errUnresolved, "<< "+ref+" >>")
+	if ctx == nil {
+		return nil, unresolved
+	}
+
+	if name := strings.TrimPrefix(ref, "parameters."); name != ref {
+		if v, ok := ctx.Parameters[name]; ok {
+			return normalizeNumber(v), nil
+		}
+		return nil, unresolved
+	}
+	if name := strings.TrimPrefix(ref, "pipeline.parameters."); name != ref {
+		if ctx.Pipeline != nil {
+			for _, p := range ctx.Pipeline.Parameters {
+				if p == nil || p.Name != name {
+					continue
+				}
+				if p.Value != nil {
+					return normalizeNumber(p.Value.DefaultValue), nil
+				}
+				return normalizeNumber(p.DefaultValue), nil
+			}
+		}
+		return nil, unresolved
+	}
+
+	switch {
+	case ref == "pipeline.id" && ctx.Pipeline != nil:
+		return ctx.Pipeline.Id, nil
+	case ref == "pipeline.number" && ctx.Pipeline != nil:
+		return float64(ctx.Pipeline.Number), nil
+	case ref == "pipeline.git.branch" && ctx.Git != nil:
+		return ctx.Git.Branch, nil
+	case ref == "pipeline.git.tag" && ctx.Git != nil:
+		return ctx.Git.Tag, nil
+	case ref == "pipeline.git.revision" && ctx.Git != nil:
+		return ctx.Git.Revision, nil
+	case ref == "pipeline.git.base_revision" && ctx.Git != nil:
+		return ctx.Git.BaseRevision, nil
+	case ref == "pipeline.project.git_url" && ctx.Project != nil:
+		return ctx.Project.GitUrl, nil
+	case ref == "pipeline.project.type" && ctx.Project != nil:
+		return ctx.Project.Vcs, nil
+	}
+	return nil, unresolved
+}
+
+// normalizeNumber returns v with Go numeric types converted to float64, as numbers are decoded from JSON.
+func normalizeNumber(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case json.Number:
+		if f, err := strconv.ParseFloat(n.String(), 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidateLogic(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "valid conditions",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - when:
+          condition:
+            and:
+              - not: {equal: [main, << pipeline.git.branch >>]}
+              - matches: {pattern: "^feature/.+$", value: << pipeline.git.branch >>}
+          steps: [checkout]
+workflows:
+  main:
+    when: {or: [true, false]}
+    jobs: [build]
+`,
+		},
+		{
+			name: "invalid operands",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - when:
+          condition: {equal: []}
+          steps: [checkout]
+      - unless:
+          condition: {not: true, or: [false]}
+          steps: [checkout]
+workflows:
+  main:
+    when:
+      matches: {pattern: "^main$"}
+    jobs: [build]
+`,
+			want: []string{
+				`8:23: error: /jobs/build/steps/0/when/condition/equal: must hold at least one value`,
+				`11:11: error: /jobs/build/steps/1/unless/condition: a logic statement must have exactly one operator, one of "and", "equal", "matches", "not" or "or"`,
+				`16:7: error: /workflows/main/when/matches: "value" is required but was not present`,
+			},
+		},
+		{
+			name: "unknown operator",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - when:
+          condition: {nott: true}
+          steps: [checkout]
+workflows:
+  main:
+    when:
+      foo: 1
+    jobs: [build]
+`,
+			want: []string{
+				`8:23: error: /jobs/build/steps/0/when/condition/nott: invalid value "nott": unknown operator, did you mean "not"?`,
+				`13:7: error: /workflows/main/when/foo: invalid value "foo": unknown operator`,
+			},
+		},
+	})
+}
+
+func TestLogicEvaluate(t *testing.T) {
+	t.Parallel()
+
+	ctx := &LogicContext{
+		Git:        &Git{Branch: "feature/login"},
+		Parameters: map[string]interface{}{"deploy": true, "count": 2},
+	}
+	tests := []struct {
+		statement string
+		want      bool
+		err       error
+	}{
+		{statement: `true`, want: true},
+		{statement: `""`, want: false},
+		{statement: `"<< parameters.deploy >>"`, want: true},
+		{statement: `{"not": "<< parameters.deploy >>"}`, want: false},
+		{statement: `{"equal": [2, "<< parameters.count >>"]}`, want: true},
+		{statement: `{"equal": ["main", "<< pipeline.git.branch >>"]}`, want: false},
+		{statement: `{"matches": {"pattern": "^feature/.+$", "value": "<< pipeline.git.branch >>"}}`, want: true},
+		{statement: `{"and": [true, {"or": [false, "<< parameters.deploy >>"]}]}`, want: true},
+		{statement: `{"and": [true, false]}`, want: false},
+		{statement: `"<< pipeline.parameters.missing >>"`, err: errUnresolved},
+	}
+	for _, tt := range tests {
+		var r LogicSchema
+		if err := json.Unmarshal([]byte(tt.statement), &r); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", tt.statement, err)
+		}
+		got, err := r.Evaluate(ctx)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Evaluate(%s) error = %v, want %v", tt.statement, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("Evaluate(%s) = %t, want %t", tt.statement, got, tt.want)
+		}
+	}
+}
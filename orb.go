@@ -0,0 +1,167 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The kinds of version an OrbReference may refer to.
+const (
+	// OrbVersionSemver a full or partial semantic version, such as "5.0.2", "5.0" or "5".
+	OrbVersionSemver = "semver"
+
+	// OrbVersionVolatile the "volatile" version, the most recently published version of the orb.
+	OrbVersionVolatile = "volatile"
+
+	// OrbVersionDev a development version, such as "dev:alpha", which expires 90 days after it was published.
+	OrbVersionDev = "dev"
+)
+
+var (
+	orbSegmentRe  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	orbSemverRe   = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.(0|[1-9][0-9]*)){0,2}$`)
+	orbDevLabelRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+)
+
+// OrbReference a reference to an orb of the registry, in the form "namespace/name@version".
+type OrbReference struct {
+	Namespace string
+	Name      string
+
+	// Version the version as written after the "@", e.g. "5.0.2", "5", "volatile" or "dev:alpha".
+	Version string
+
+	// VersionKind the kind of Version, one of OrbVersionSemver, OrbVersionVolatile and OrbVersionDev.
+	VersionKind string
+}
+
+// ParseOrbReference parses the orb reference s, such as "circleci/node@5.0.2".
+//
+// The version may be a full or partial semantic version ("@5.0.2", "@5.0", "@5"), "volatile", or a development label ("@dev:alpha").
+// The returned error is an *InvalidValueError describing why s is malformed, with an empty path.
+func ParseOrbReference(s string) (*OrbReference, error) {
+	malformed := func(reason string) error {
+		return &InvalidValueError{Value: s, Reason: reason}
+	}
+
+	ref, version, ok := cut(s, "@")
+	if !ok || version == "" {
+		return nil, malformed(`missing version, as in "circleci/node@5.0.2"`)
+	}
+	namespace, name, ok := cut(ref, "/")
+	if !ok {
+		return nil, malformed(`missing namespace, as in "circleci/node@5.0.2"`)
+	}
+	if !orbSegmentRe.MatchString(namespace) {
+		return nil, malformed("namespace must consist of lowercase letters, digits, \"-\" and \"_\"")
+	}
+	if !orbSegmentRe.MatchString(name) {
+		return nil, malformed("name must consist of lowercase letters, digits, \"-\" and \"_\"")
+	}
+
+	r := &OrbReference{Namespace: namespace, Name: name, Version: version}
+	switch {
+	case version == OrbVersionVolatile:
+		r.VersionKind = OrbVersionVolatile
+	case strings.HasPrefix(version, "dev:"):
+		if label := strings.TrimPrefix(version, "dev:"); !orbDevLabelRe.MatchString(label) && !isParameterExpression(label) {
+			return nil, malformed("invalid development label")
+		}
+		r.VersionKind = OrbVersionDev
+	case orbSemverRe.MatchString(version):
+		r.VersionKind = OrbVersionSemver
+	default:
+		return nil, malformed(`version must be a semantic version, "volatile" or "dev:<label>"`)
+	}
+	return r, nil
+}
+
+func (r *OrbReference) String() string {
+	return r.Namespace + "/" + r.Name + "@" + r.Version
+}
+
+// Pinned reports whether r refers to a single published version of the orb, that is whether its version is a full semantic version such as "5.0.2".
+func (r *OrbReference) Pinned() bool {
+	return r.VersionKind == OrbVersionSemver && strings.Count(r.Version, ".") == 2
+}
+
+// Reference parses OrbImport. See ParseOrbReference.
+//
+// It returns nil and no error for an inline orb.
+func (r *ConfigOrbImport) Reference() (*OrbReference, error) {
+	if r.Inline != nil {
+		return nil, nil
+	}
+	return ParseOrbReference(r.OrbImport)
+}
+
+// cut slices s around the first instance of sep, as strings.Cut of Go 1.18.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// checkOrbs reports orb references, of the config and of its inline orbs, that are malformed.
+//
+// References containing a parameter expression are not checked.
+func checkOrbs(cfg *CircleCIConfigSchema, report *Report) {
+	walkOrbReferences(cfg.Orbs, jsonPointer("orbs"), func(ptr string, orb *ConfigOrbImport) {
+		if _, err := orb.Reference(); err != nil {
+			report.add(SeverityError, locate(ptr, err))
+		}
+	})
+}
+
+// checkOrbPinning warns about orb references that are not pinned to a full semantic version, such as "circleci/node@5" or
+// "circleci/node@volatile", as the orb may change under the config without notice. It is run by Validator.WarnUnpinnedOrbs.
+func checkOrbPinning(cfg *CircleCIConfigSchema, report *Report) {
+	walkOrbReferences(cfg.Orbs, jsonPointer("orbs"), func(ptr string, orb *ConfigOrbImport) {
+		ref, err := orb.Reference()
+		if err != nil || ref.Pinned() {
+			return
+		}
+		reason := "orb is not pinned to a full semantic version"
+		switch ref.VersionKind {
+		case OrbVersionVolatile:
+			reason += `; "volatile" always resolves to the latest version`
+		case OrbVersionDev:
+			reason += "; development versions are mutable and expire after 90 days"
+		default:
+			reason += "; @" + ref.Version + " resolves to the latest " + ref.Version + ".x release"
+		}
+		report.add(SeverityWarning, &InvalidValueError{Path: ptr, Value: orb.OrbImport, Reason: reason})
+	})
+}
+
+// walkOrbReferences calls fn with every orb of orbs imported from the registry, and the JSON pointer to it,
+// descending into inline orbs, whose orbs section is found at ptr.
+func walkOrbReferences(orbs *OrbSchema, ptr string, fn func(ptr string, orb *ConfigOrbImport)) {
+	if orbs == nil {
+		return
+	}
+	for _, alias := range sortedOrbAliases(orbs) {
+		orb := orbs.AdditionalProperties[alias]
+		switch {
+		case orb == nil:
+		case orb.Inline != nil:
+			walkOrbReferences(orb.Inline.Orbs, ptr+jsonPointer(alias, "orbs"), fn)
+		case !isParameterExpression(orb.OrbImport):
+			fn(ptr+jsonPointer(alias), orb)
+		}
+	}
+}
+
+func sortedOrbAliases(s *OrbSchema) []string {
+	names := make([]string, 0, len(s.AdditionalProperties))
+	for name := range s.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
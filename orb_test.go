@@ -0,0 +1,197 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeOrbs(t *testing.T) {
+	t.Parallel()
+
+	config := `
+version: 2.1
+orbs:
+  node: circleci/node@5.0.2
+  local:
+    commands:
+      hello:
+        steps:
+          - run: echo hello
+    jobs:
+      greet:
+        docker: [{image: a}]
+        steps: [hello]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [local/hello]
+workflows:
+  main:
+    jobs: [build, local/greet]
+`
+	doc, err := NewDecoder(strings.NewReader(config)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	orbs := doc.Config.Orbs.AdditionalProperties
+	if orb := orbs["node"]; orb == nil || orb.OrbImport != "circleci/node@5.0.2" || orb.Inline != nil {
+		t.Errorf("orb node = %#v, want an import of circleci/node@5.0.2", orb)
+	}
+	orb := orbs["local"]
+	if orb == nil || orb.Inline == nil {
+		t.Fatalf("orb local = %#v, want an inline orb", orb)
+	}
+	if orb.Inline.Commands == nil || orb.Inline.Commands.AdditionalProperties["hello"] == nil {
+		t.Errorf("commands of the inline orb were not decoded")
+	}
+	if orb.Inline.Jobs == nil || orb.Inline.Jobs.AdditionalProperties["greet"] == nil {
+		t.Errorf("jobs of the inline orb were not decoded")
+	}
+}
+
+func TestValidateOrbs(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "valid",
+			config: `
+version: 2.1
+orbs:
+  node: circleci/node@5.0.2
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [node/install-packages]
+workflows:
+  main:
+    jobs: [build, node/test]
+`,
+		},
+		{
+			name: "no such job in inline orb",
+			config: `
+version: 2.1
+orbs:
+  local:
+    jobs:
+      greet:
+        docker: [{image: a}]
+        steps: [checkout]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build, local/great]
+`,
+			want: []string{
+				`15:19: error: /workflows/main/jobs/1/local~1great: invalid value "local/great": no such job in orb "local", did you mean "local/greet"?`,
+			},
+		},
+		{
+			name: "invalid orbs",
+			config: `
+version: 2.1
+orbs:
+  local:
+    jobs:
+      greet:
+        docker: [{image: a}]
+    executors:
+      none: {}
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`6:7: error: /orbs/local/jobs/greet: "steps" is required but was not present`,
+				`9:7: error: /orbs/local/executors/none: one of "docker", "machine" or "macos" is required`,
+			},
+		},
+	})
+}
+
+func TestParseOrbReference(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s      string
+		kind   string
+		pinned bool
+		err    string
+	}{
+		{s: "circleci/node@5.0.2", kind: OrbVersionSemver, pinned: true},
+		{s: "circleci/node@5.0", kind: OrbVersionSemver},
+		{s: "circleci/node@5", kind: OrbVersionSemver},
+		{s: "circleci/node@volatile", kind: OrbVersionVolatile},
+		{s: "my-org/my_orb@dev:alpha", kind: OrbVersionDev},
+		{s: "circleci/node", err: `invalid value "circleci/node": missing version, as in "circleci/node@5.0.2"`},
+		{s: "node@5.0.2", err: `invalid value "node@5.0.2": missing namespace, as in "circleci/node@5.0.2"`},
+		{s: "CircleCI/node@5.0.2", err: `invalid value "CircleCI/node@5.0.2": namespace must consist of lowercase letters, digits, "-" and "_"`},
+		{s: "circleci/node@5.0.02", err: `invalid value "circleci/node@5.0.02": version must be a semantic version, "volatile" or "dev:<label>"`},
+	}
+	for _, tt := range tests {
+		ref, err := ParseOrbReference(tt.s)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseOrbReference(%q) error = %v, want %s", tt.s, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseOrbReference(%q) error = %v", tt.s, err)
+			continue
+		}
+		if ref.String() != tt.s || ref.VersionKind != tt.kind || ref.Pinned() != tt.pinned {
+			t.Errorf("ParseOrbReference(%q) = %+v, Pinned() = %t, want kind %s, pinned %t", tt.s, ref, ref.Pinned(), tt.kind, tt.pinned)
+		}
+	}
+}
+
+func TestValidateOrbReferences(t *testing.T) {
+	config := `
+version: 2.1
+orbs:
+  node: circleci/node@5
+  go: circleci/go@volatile
+  aws: circleci/aws-cli
+  local:
+    orbs:
+      slack: circleci/slack@dev:alpha
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name:   "malformed",
+			config: config,
+			want: []string{
+				`6:3: error: /orbs/aws: invalid value "circleci/aws-cli": missing version, as in "circleci/node@5.0.2"`,
+			},
+		},
+	})
+	runValidateTests(t, &Validator{WarnUnpinnedOrbs: true}, []validateTest{
+		{
+			name:   "unpinned",
+			config: config,
+			want: []string{
+				`4:3: warning: /orbs/node: invalid value "circleci/node@5": orb is not pinned to a full semantic version; @5 resolves to the latest 5.x release`,
+				`5:3: warning: /orbs/go: invalid value "circleci/go@volatile": orb is not pinned to a full semantic version; "volatile" always resolves to the latest version`,
+				`6:3: error: /orbs/aws: invalid value "circleci/aws-cli": missing version, as in "circleci/node@5.0.2"`,
+				`9:7: warning: /orbs/local/orbs/slack: invalid value "circleci/slack@dev:alpha": orb is not pinned to a full semantic version; development versions are mutable and expire after 90 days`,
+			},
+		},
+	})
+}
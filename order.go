@@ -0,0 +1,142 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// objectKeys returns the keys of the JSON object b in the order they appear, without duplicates.
+// It returns nil if b is not a valid JSON object.
+func objectKeys(b []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		k, ok := tok.(string)
+		if !ok {
+			return nil
+		}
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil
+		}
+	}
+	return keys
+}
+
+// orderKeys sorts keys so that the ones listed in order come first, in that order, followed by the others sorted alphabetically.
+func orderKeys(keys, order []string) []string {
+	rank := make(map[string]int, len(order))
+	for i, k := range order {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, iok := rank[keys[i]]
+		rj, jok := rank[keys[j]]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// sourceLayout keeps how a decoded object was laid out in the source: the order of its keys, and whether it was written in a short form,
+// such as the step "checkout" for `checkout: {}`. MarshalJSON writes the object back the same way, so that a decoded config survives a
+// round trip through yaml.Marshal.
+//
+// An object that was not decoded has no layout, and is written in the order of its fields.
+type sourceLayout struct {
+	keys []string
+
+	short json.RawMessage
+	long  []byte
+}
+
+// setKeyOrder keeps the order of the keys of the JSON object b. An object with a single key has no order to keep.
+func (l *sourceLayout) setKeyOrder(b []byte) {
+	if keys := objectKeys(b); len(keys) > 1 {
+		l.keys = keys
+	}
+}
+
+// setShortForm keeps that the object was written as short, a short form of the object whose JSON encoding is long.
+func (l *sourceLayout) setShortForm(short, long []byte) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, long); err != nil {
+		return
+	}
+	l.short = append(json.RawMessage(nil), short...)
+	l.long = buf.Bytes()
+}
+
+// restoreLayout returns the JSON object b, as written by MarshalJSON, laid out as the object was in the source.
+//
+// The object is written in its short form only if b is still the encoding that short form stands for.
+// Keys that were not in the source are written after those that were, in the order of b.
+func (l *sourceLayout) restoreLayout(b []byte) ([]byte, error) {
+	if l.short != nil {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err == nil && bytes.Equal(buf.Bytes(), l.long) {
+			return l.short, nil
+		}
+	}
+	if len(l.keys) == 0 {
+		return b, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	keys := objectKeys(b)
+	rank := make(map[string]int, len(l.keys))
+	for i, k := range l.keys {
+		rank[k] = i
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		ri, iok := rank[keys[i]]
+		rj, jok := rank[keys[j]]
+		if iok && jok {
+			return ri < rj
+		}
+		return iok && !jok
+	})
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(b)))
+	buf.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		buf.Write(fields[k])
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
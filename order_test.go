@@ -0,0 +1,189 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRoundTripKeepsKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	config := `version: 2.1
+jobs:
+  test:
+    docker:
+      - image: a
+    steps:
+      - checkout
+  build:
+    docker:
+      - image: a
+    steps:
+      - checkout
+workflows:
+  nightly:
+    jobs:
+      - test
+  main:
+    jobs:
+      - test
+      - build:
+          requires:
+            - test
+`
+	doc, err := NewDecoder(strings.NewReader(config)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	b, err := yaml.Marshal(doc.Config)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	js, _, err := nodeToJSON(&root)
+	if err != nil {
+		t.Fatalf("nodeToJSON() error = %v", err)
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(js, &sections); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	for _, tt := range []struct {
+		section string
+		want    []string
+	}{
+		{section: "jobs", want: []string{"test", "build"}},
+		{section: "workflows", want: []string{"nightly", "main"}},
+	} {
+		if got := objectKeys(sections[tt.section]); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("keys of %s = %q, want %q", tt.section, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTripRealConfig(t *testing.T) {
+	t.Parallel()
+
+	config := `version: 2.1
+
+orbs:
+  codecov: codecov/codecov@3.2.2
+
+executors:
+  golang:
+    parameters:
+      go:
+        type: string
+        default: "1.17"
+    docker:
+      - image: cimg/go:<< parameters.go >>
+    working_directory: ~/circleci-validator
+
+commands:
+  go-mod-download:
+    steps:
+      - restore_cache:
+          key: go-mod-v1-{{ checksum "go.sum" }}
+      - run:
+          name: Download modules
+          command: go mod download
+      - save_cache:
+          key: go-mod-v1-{{ checksum "go.sum" }}
+          paths:
+            - /home/circleci/go/pkg/mod
+
+jobs:
+  test:
+    parameters:
+      go:
+        type: string
+    executor:
+      name: golang
+      go: << parameters.go >>
+    environment:
+      CGO_ENABLED: "0"
+      GOFLAGS: -mod=readonly
+    steps:
+      - checkout
+      - go-mod-download
+      - run: go vet ./...
+      - run:
+          name: Test
+          command: go test -race -coverprofile=coverage.out ./...
+          no_output_timeout: 15m
+      - store_test_results:
+          path: /tmp/test-results
+      - codecov/upload:
+          file: coverage.out
+  lint:
+    executor: golang
+    steps:
+      - checkout
+      - run: make lint
+
+workflows:
+  test:
+    jobs:
+      - lint
+      - test:
+          name: test-<< matrix.go >>
+          matrix:
+            parameters:
+              go: ["1.17", "1.16"]
+          requires:
+            - lint
+  nightly:
+    triggers:
+      - schedule:
+          cron: "0 3 * * *"
+          filters:
+            branches:
+              only:
+                - main
+    jobs:
+      - test:
+          go: "1.17"
+`
+	dec := NewDecoder(strings.NewReader(config))
+	dec.DisallowUnknownFields()
+	doc, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	b, err := yaml.Marshal(doc.Config)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+
+	// compare the documents by their JSON encodings, which keep the order of keys but not the style of the YAML
+	want, _, err := yamlToJSON([]byte(config))
+	if err != nil {
+		t.Fatalf("yamlToJSON() error = %v", err)
+	}
+	got, _, err := yamlToJSON(b)
+	if err != nil {
+		t.Fatalf("yamlToJSON() error = %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("yaml.Marshal() =\n%s\nwant the decoded config:\n%s", b, config)
+	}
+}
+
+func TestOrderKeys(t *testing.T) {
+	t.Parallel()
+
+	got := orderKeys([]string{"d", "a", "c", "b"}, []string{"c", "x", "a"})
+	if want := "c,a,b,d"; strings.Join(got, ",") != want {
+		t.Errorf("orderKeys() = %q, want %s", got, want)
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// The types of a parameter declared by a job, command or executor.
+const (
+	ParameterTypeString     = "string"
+	ParameterTypeBoolean    = "boolean"
+	ParameterTypeInteger    = "integer"
+	ParameterTypeEnum       = "enum"
+	ParameterTypeExecutor   = "executor"
+	ParameterTypeSteps      = "steps"
+	ParameterTypeEnvVarName = "env_var_name"
+)
+
+// parameterTypes every valid ParameterSchemaItem.Type.
+var parameterTypes = []string{
+	ParameterTypeString,
+	ParameterTypeBoolean,
+	ParameterTypeInteger,
+	ParameterTypeEnum,
+	ParameterTypeExecutor,
+	ParameterTypeSteps,
+	ParameterTypeEnvVarName,
+}
+
+var envVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Required reports whether an argument must be passed to the parameter, because it has no default.
+func (r *ParameterSchemaItem) Required() bool {
+	return r.Default == nil
+}
+
+// CheckArgument checks that the decoded JSON value v is a valid argument for the parameter.
+//
+// Parameter expressions, such as "<< pipeline.parameters.name >>", are accepted for every type, as their value is only known once the config is processed.
+// The returned error is nil, a *TypeMismatchError or an *InvalidValueError, with an empty path.
+func (r *ParameterSchemaItem) CheckArgument(v interface{}) error {
+	if s, ok := v.(string); ok && isParameterExpression(s) {
+		return nil
+	}
+
+	var expected string
+	switch r.Type {
+	case ParameterTypeString:
+		if _, ok := v.(string); !ok {
+			expected = "string"
+		}
+	case ParameterTypeBoolean:
+		if _, ok := v.(bool); !ok {
+			expected = "boolean"
+		}
+	case ParameterTypeInteger:
+		if f, ok := v.(float64); !ok || f != math.Trunc(f) {
+			expected = "integer"
+		}
+	case ParameterTypeEnum:
+		s, ok := v.(string)
+		if !ok {
+			expected = "string"
+			break
+		}
+		for _, e := range r.Enum {
+			if s == e {
+				return nil
+			}
+		}
+		allowed := make([]string, len(r.Enum))
+		for i, e := range r.Enum {
+			allowed[i] = strconv.Quote(e)
+		}
+		return &InvalidValueError{
+			Value:      s,
+			Reason:     fmt.Sprintf("must be one of %s", joinList(allowed)),
+			Suggestion: suggest(s, r.Enum),
+		}
+	case ParameterTypeExecutor:
+		switch e := v.(type) {
+		case string:
+		case map[string]interface{}:
+			if _, ok := e["name"].(string); !ok {
+				return &MissingFieldError{Path: jsonPointer("name"), Field: "name"}
+			}
+		default:
+			expected = "string or object"
+		}
+	case ParameterTypeSteps:
+		if _, ok := v.([]interface{}); !ok {
+			expected = "array"
+		}
+	case ParameterTypeEnvVarName:
+		s, ok := v.(string)
+		if !ok {
+			expected = "string"
+			break
+		}
+		if !envVarNameRe.MatchString(s) {
+			return &InvalidValueError{Value: s, Reason: "not a valid environment variable name"}
+		}
+	}
+	if expected != "" {
+		return &TypeMismatchError{Expected: expected, Actual: jsonValueType(v)}
+	}
+	return nil
+}
+
+// joinList joins items as in "a, b or c".
+func joinList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	s := items[0]
+	for _, item := range items[1 : len(items)-1] {
+		s += ", " + item
+	}
+	return s + " or " + items[len(items)-1]
+}
+
+// checkParameters reports invalid parameter declarations of jobs, commands and executors, and workflow jobs that pass invalid arguments to their job.
+//
+// Arguments passed to commands and executors are checked by checkCommands and checkExecutors.
+func checkParameters(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Commands != nil {
+		for _, name := range sortedCommandNames(cfg.Commands) {
+			if cmd := cfg.Commands.AdditionalProperties[name]; cmd != nil {
+				checkParameterDeclarations(report, jsonPointer("commands", name, "parameters"), cmd.Parameters)
+			}
+		}
+	}
+	if cfg.Executors != nil {
+		for _, name := range sortedExecutorNames(cfg.Executors) {
+			if exec := cfg.Executors.AdditionalProperties[name]; exec != nil {
+				checkParameterDeclarations(report, jsonPointer("executors", name, "parameters"), exec.Parameters)
+			}
+		}
+	}
+	if cfg.Jobs == nil {
+		return
+	}
+	for _, name := range sortedJobNames(cfg.Jobs) {
+		if job := cfg.Jobs.AdditionalProperties[name]; job != nil {
+			checkParameterDeclarations(report, jsonPointer("jobs", name, "parameters"), job.Parameters)
+		}
+	}
+
+	if cfg.Workflows == nil {
+		return
+	}
+	for _, wfName := range sortedWorkflowNames(cfg.Workflows) {
+		wf := cfg.Workflows.AdditionalProperties[wfName]
+		if wf == nil {
+			continue
+		}
+		for i, wfJob := range wf.Jobs {
+			if wfJob == nil {
+				continue
+			}
+			for _, name := range sortedWorkflowJobNames(wfJob) {
+				job := cfg.Jobs.AdditionalProperties[name]
+				if job == nil {
+					continue
+				}
+				ptr := jsonPointer("workflows", wfName, "jobs", strconv.Itoa(i), name)
+				checkWorkflowJobArguments(report, ptr, job.Parameters, wfJob.AdditionalProperties[name])
+			}
+		}
+	}
+}
+
+// checkWorkflowJobArguments checks the arguments passed by the workflow job item at the JSON pointer ptr, including those of its matrix, against params.
+func checkWorkflowJobArguments(report *Report, ptr string, params *ParameterSchema, item *WorkflowJobSchemaItem) {
+	if item == nil {
+		checkArguments(report, ptr, params, nil, nil)
+		return
+	}
+
+	provided := make(map[string]bool)
+	if item.Matrix != nil {
+		names := make([]string, 0, len(item.Matrix.Parameters))
+		for name := range item.Matrix.Parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			provided[name] = true
+			matrixPtr := ptr + jsonPointer("matrix", "parameters", name)
+			decl := lookupParameter(params, name)
+			if decl == nil {
+				report.add(SeverityError, &UnknownFieldError{
+					Path:       matrixPtr,
+					Field:      name,
+					Suggestion: suggest(name, parameterNames(params)),
+				})
+				continue
+			}
+			for j, v := range item.Matrix.Parameters[name] {
+				if err := decl.CheckArgument(v); err != nil {
+					report.add(SeverityError, locate(matrixPtr+jsonPointer(strconv.Itoa(j)), err))
+				}
+			}
+		}
+	}
+	checkArguments(report, ptr, params, item.AdditionalProperties, provided)
+}
+
+// checkParameterDeclarations reports invalid declarations among params, found at the JSON pointer ptr.
+func checkParameterDeclarations(report *Report, ptr string, params *ParameterSchema) {
+	for _, name := range parameterNames(params) {
+		decl := params.AdditionalProperties[name]
+		if decl == nil {
+			continue
+		}
+		declPtr := ptr + jsonPointer(name)
+		if !isParameterType(decl.Type) {
+			report.add(SeverityError, &InvalidValueError{
+				Path:       declPtr + jsonPointer("type"),
+				Value:      decl.Type,
+				Reason:     "unknown parameter type",
+				Suggestion: suggest(decl.Type, parameterTypes),
+			})
+			continue
+		}
+		if decl.Type == ParameterTypeEnum && len(decl.Enum) == 0 {
+			report.add(SeverityError, &MissingFieldError{Path: declPtr + jsonPointer("enum"), Field: "enum"})
+			continue
+		}
+		if decl.Default != nil {
+			if err := decl.CheckArgument(decl.Default); err != nil {
+				report.add(SeverityError, locate(declPtr+jsonPointer("default"), err))
+			}
+		}
+	}
+}
+
+// checkArguments checks args, passed at the JSON pointer ptr, against params.
+//
+// It reports arguments that are not declared, arguments of the wrong type, and required parameters that are neither in args nor in provided.
+func checkArguments(report *Report, ptr string, params *ParameterSchema, args map[string]interface{}, provided map[string]bool) {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		decl := lookupParameter(params, name)
+		if decl == nil {
+			report.add(SeverityError, &UnknownFieldError{
+				Path:       ptr + jsonPointer(name),
+				Field:      name,
+				Suggestion: suggest(name, parameterNames(params)),
+			})
+			continue
+		}
+		if err := decl.CheckArgument(args[name]); err != nil {
+			report.add(SeverityError, locate(ptr+jsonPointer(name), err))
+		}
+	}
+
+	for _, name := range parameterNames(params) {
+		decl := params.AdditionalProperties[name]
+		if decl == nil || !decl.Required() || provided[name] {
+			continue
+		}
+		if _, ok := args[name]; !ok {
+			report.add(SeverityError, &MissingFieldError{Path: ptr + jsonPointer(name), Field: name})
+		}
+	}
+}
+
+func lookupParameter(params *ParameterSchema, name string) *ParameterSchemaItem {
+	if params == nil {
+		return nil
+	}
+	return params.AdditionalProperties[name]
+}
+
+func isParameterType(s string) bool {
+	for _, t := range parameterTypes {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+func parameterNames(params *ParameterSchema) []string {
+	if params == nil {
+		return nil
+	}
+	names := make([]string, 0, len(params.AdditionalProperties))
+	for name := range params.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedExecutorNames(s *ExecutorSchema) []string {
+	names := make([]string, 0, len(s.AdditionalProperties))
+	for name := range s.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import "testing"
+
+func TestCheckArgument(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		param *ParameterSchemaItem
+		arg   interface{}
+		want  string
+	}{
+		{param: &ParameterSchemaItem{Type: ParameterTypeString}, arg: "a"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeString}, arg: 1.0, want: "expected string but got number"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeBoolean}, arg: "<< pipeline.parameters.flag >>"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeBoolean}, arg: "yes", want: "expected boolean but got string"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeInteger}, arg: 2.0},
+		{param: &ParameterSchemaItem{Type: ParameterTypeInteger}, arg: 2.5, want: "expected integer but got number"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeEnum, Enum: []string{"small", "large"}}, arg: "large"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeEnum, Enum: []string{"small", "large"}}, arg: "lrge", want: `invalid value "lrge": must be one of "small" or "large", did you mean "large"?`},
+		{param: &ParameterSchemaItem{Type: ParameterTypeExecutor}, arg: map[string]interface{}{"name": "go"}},
+		{param: &ParameterSchemaItem{Type: ParameterTypeExecutor}, arg: map[string]interface{}{}, want: `"name" is required but was not present`},
+		{param: &ParameterSchemaItem{Type: ParameterTypeSteps}, arg: []interface{}{"checkout"}},
+		{param: &ParameterSchemaItem{Type: ParameterTypeSteps}, arg: "checkout", want: "expected array but got string"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeEnvVarName}, arg: "AWS_KEY"},
+		{param: &ParameterSchemaItem{Type: ParameterTypeEnvVarName}, arg: "1KEY", want: `invalid value "1KEY": not a valid environment variable name`},
+	}
+	for _, tt := range tests {
+		err := tt.param.CheckArgument(tt.arg)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("CheckArgument(%#v) for a %s parameter = %q, want %q", tt.arg, tt.param.Type, got, tt.want)
+		}
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "invalid declarations and arguments",
+			config: `
+version: 2.1
+jobs:
+  build:
+    parameters:
+      size: {type: enum, enum: [small, large], default: huge}
+      count: {type: integer, default: "2"}
+      tag: {type: string}
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build:
+          count: 3
+`,
+			want: []string{
+				`6:48: error: /jobs/build/parameters/size/default: invalid value "huge": must be one of "small" or "large"`,
+				`7:30: error: /jobs/build/parameters/count/default: expected integer but got string`,
+				`14:9: error: /workflows/main/jobs/0/build: "tag" is required but was not present`,
+			},
+		},
+	})
+}
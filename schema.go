@@ -8,11 +8,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+
+	"gopkg.in/yaml.v3"
 )
 
 // AddSSHKeysCommandSchema json schema for the AddSSHKeys command.
 type AddSSHKeysCommandSchema struct {
 	AddSshKeys *AddSSHKeysParameters `json:"add_ssh_keys"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *AddSSHKeysCommandSchema) MarshalJSON() ([]byte, error) {
@@ -36,8 +43,7 @@ func (r *AddSSHKeysCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *AddSSHKeysCommandSchema) UnmarshalJSON(b []byte) error {
@@ -46,26 +52,41 @@ func (r *AddSSHKeysCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "add_ssh_keys":
-			if err := json.Unmarshal([]byte(v), &r.AddSshKeys); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.AddSshKeys); err != nil {
+				errs.addAt(k, err)
 			}
 			add_ssh_keysReceived = true
+		default:
+			errs.add(unknownField(k, "add_ssh_keys"))
 		}
 	}
 	// check if add_ssh_keys (a required property) was received
 	if !add_ssh_keysReceived {
-		return errors.New("\"add_ssh_keys\" is required but was not present")
+		errs.add(missingField("add_ssh_keys"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *AddSSHKeysCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *AddSSHKeysCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // AttachCommandSchema json schema for the attach command.
 type AttachCommandSchema struct {
 	AttachWorkspace *AttachParameters `json:"attach_workspace"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *AttachCommandSchema) MarshalJSON() ([]byte, error) {
@@ -89,8 +110,7 @@ func (r *AttachCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *AttachCommandSchema) UnmarshalJSON(b []byte) error {
@@ -99,26 +119,41 @@ func (r *AttachCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "attach_workspace":
-			if err := json.Unmarshal([]byte(v), &r.AttachWorkspace); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.AttachWorkspace); err != nil {
+				errs.addAt(k, err)
 			}
 			attach_workspaceReceived = true
+		default:
+			errs.add(unknownField(k, "attach_workspace"))
 		}
 	}
 	// check if attach_workspace (a required property) was received
 	if !attach_workspaceReceived {
-		return errors.New("\"attach_workspace\" is required but was not present")
+		errs.add(missingField("attach_workspace"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *AttachCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *AttachCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // CheckoutCommandSchema
 type CheckoutCommandSchema struct {
 	Checkout *CheckoutParameter `json:"checkout"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *CheckoutCommandSchema) MarshalJSON() ([]byte, error) {
@@ -142,8 +177,7 @@ func (r *CheckoutCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *CheckoutCommandSchema) UnmarshalJSON(b []byte) error {
@@ -152,103 +186,327 @@ func (r *CheckoutCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "checkout":
-			if err := json.Unmarshal([]byte(v), &r.Checkout); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Checkout); err != nil {
+				errs.addAt(k, err)
 			}
 			checkoutReceived = true
+		default:
+			errs.add(unknownField(k, "checkout"))
 		}
 	}
 	// check if checkout (a required property) was received
 	if !checkoutReceived {
-		return errors.New("\"checkout\" is required but was not present")
+		errs.add(missingField("checkout"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
 }
 
-// CommandSchema
-type CommandSchema struct{}
+func (r *CheckoutCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
 
-// CircleCIConfigSchema json schema for the circleci config.
-type CircleCIConfigSchema struct {
-	Commands  *CommandSchema     `json:"commands,omitempty"`
-	Jobs      *JobSchema         `json:"jobs"`
-	Orbs      []*ConfigOrbImport `json:"orbs,omitempty"`
-	Setup     bool               `json:"setup"`
-	Version   float64            `json:"version"`
-	Workflows *WorkflowSchema    `json:"workflows"`
+func (r *CheckoutCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
-func (r *CircleCIConfigSchema) MarshalJSON() ([]byte, error) {
+// CommandSchema json schema for the reusable commands of a config, keyed by name.
+type CommandSchema struct {
+	AdditionalProperties map[string]*CommandSchemaItem `json:"-,omitempty"`
+
+	// Order the order in which the keys of AdditionalProperties appeared in the decoded source.
+	// Keys missing from Order are marshalled after it, sorted.
+	Order []string `json:"-"`
+
+	unknownFields
+}
+
+func (r *CommandSchema) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal the "commands" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal any additional Properties, in source order
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
 	}
-	buf.WriteString("\"commands\": ")
-	if tmp, err := json.Marshal(r.Commands); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	for _, k := range orderKeys(keys, r.Order) {
+		v := r.AdditionalProperties[k]
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// "Jobs" field is required
-	if r.Jobs == nil {
-		return nil, errors.New("jobs is a required field")
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (r *CommandSchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
 	}
-	// Marshal the "jobs" field
-	if comma {
-		buf.WriteString(",")
+	r.Order = objectKeys(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		default:
+			// an additional "*CommandSchemaItem" value
+			var additionalValue *CommandSchemaItem
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
+			}
+			if r.AdditionalProperties == nil {
+				r.AdditionalProperties = make(map[string]*CommandSchemaItem)
+			}
+			r.AdditionalProperties[k] = additionalValue
+		}
 	}
-	buf.WriteString("\"jobs\": ")
-	if tmp, err := json.Marshal(r.Jobs); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *CommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *CommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// CommandSchemaItem a reusable command: a sequence of steps that jobs and other commands invoke as a single step.
+type CommandSchemaItem struct {
+	Description string           `json:"description,omitempty"`
+	Parameters  *ParameterSchema `json:"parameters,omitempty"`
+	Steps       Steps            `json:"steps"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *CommandSchemaItem) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "description" field
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// Marshal the "orbs" field
+	// Marshal the "parameters" field
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// "Steps" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "steps" field
 	if comma {
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"orbs\": ")
-	if tmp, err := json.Marshal(r.Orbs); err != nil {
+	buf.WriteString("\"steps\": ")
+	if tmp, err := json.Marshal(r.Steps); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
 	}
 	comma = true
-	// "Setup" field is required
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *CommandSchemaItem) UnmarshalJSON(b []byte) error {
+	stepsReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "description":
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
+			}
+		case "parameters":
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
+			}
+		case "steps":
+			if err := unmarshalValue(v, &r.Steps); err != nil {
+				errs.addAt(k, err)
+			}
+			stepsReceived = true
+		default:
+			errs.add(unknownField(k, "description", "parameters", "steps"))
+		}
+	}
+	// check if steps (a required property) was received
+	if !stepsReceived {
+		errs.add(missingField("steps"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *CommandSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *CommandSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// CircleCIConfigSchema json schema for the circleci config.
+type CircleCIConfigSchema struct {
+	Commands  *CommandSchema  `json:"commands,omitempty"`
+	Executors *ExecutorSchema `json:"executors,omitempty"`
+	Jobs      *JobSchema      `json:"jobs"`
+	Orbs      *OrbSchema      `json:"orbs,omitempty"`
+
+	// Parameters the pipeline parameters, referred to as "<< pipeline.parameters.name >>".
+	Parameters *ParameterSchema `json:"parameters,omitempty"`
+	Setup      bool             `json:"setup,omitempty"`
+	Version    float64          `json:"version"`
+	Workflows  *WorkflowSchema  `json:"workflows"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *CircleCIConfigSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Version" field is required
 	// only required object types supported for marshal checking (for now)
-	// Marshal the "setup" field
+	// Marshal the "version" field
 	if comma {
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"setup\": ")
-	if tmp, err := json.Marshal(r.Setup); err != nil {
+	buf.WriteString("\"version\": ")
+	if tmp, err := json.Marshal(r.Version); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
 	}
 	comma = true
-	// "Version" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "version" field
+	// Marshal the "setup" field
+	if r.Setup {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"setup\": ")
+		if tmp, err := json.Marshal(r.Setup); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "commands" field
+	if r.Commands != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"commands\": ")
+		if tmp, err := json.Marshal(r.Commands); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "executors" field
+	if r.Executors != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"executors\": ")
+		if tmp, err := json.Marshal(r.Executors); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// "Jobs" field is required
+	if r.Jobs == nil {
+		return nil, errors.New("jobs is a required field")
+	}
+	// Marshal the "jobs" field
 	if comma {
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"version\": ")
-	if tmp, err := json.Marshal(r.Version); err != nil {
+	buf.WriteString("\"jobs\": ")
+	if tmp, err := json.Marshal(r.Jobs); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "orbs" field
+	if r.Orbs != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"orbs\": ")
+		if tmp, err := json.Marshal(r.Orbs); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "parameters" field
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
 	// "Workflows" field is required
 	if r.Workflows == nil {
 		return nil, errors.New("workflows is a required field")
@@ -266,75 +524,91 @@ func (r *CircleCIConfigSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *CircleCIConfigSchema) UnmarshalJSON(b []byte) error {
 	jobsReceived := false
-	setupReceived := false
 	versionReceived := false
 	workflowsReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "commands":
-			if err := json.Unmarshal([]byte(v), &r.Commands); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Commands); err != nil {
+				errs.addAt(k, err)
+			}
+		case "executors":
+			if err := unmarshalValue(v, &r.Executors); err != nil {
+				errs.addAt(k, err)
 			}
 		case "jobs":
-			if err := json.Unmarshal([]byte(v), &r.Jobs); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Jobs); err != nil {
+				errs.addAt(k, err)
 			}
 			jobsReceived = true
 		case "orbs":
-			if err := json.Unmarshal([]byte(v), &r.Orbs); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Orbs); err != nil {
+				errs.addAt(k, err)
+			}
+		case "parameters":
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 		case "setup":
-			if err := json.Unmarshal([]byte(v), &r.Setup); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Setup); err != nil {
+				errs.addAt(k, err)
 			}
-			setupReceived = true
 		case "version":
-			if err := json.Unmarshal([]byte(v), &r.Version); err != nil {
-				return err
+			if err := unmarshalVersion(v, &r.Version); err != nil {
+				errs.addAt(k, err)
 			}
 			versionReceived = true
 		case "workflows":
-			if err := json.Unmarshal([]byte(v), &r.Workflows); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Workflows); err != nil {
+				errs.addAt(k, err)
 			}
 			workflowsReceived = true
+		default:
+			errs.add(unknownField(k, "commands", "executors", "jobs", "orbs", "parameters", "setup", "version", "workflows"))
 		}
 	}
 	// check if jobs (a required property) was received
 	if !jobsReceived {
-		return errors.New("\"jobs\" is required but was not present")
-	}
-	// check if setup (a required property) was received
-	if !setupReceived {
-		return errors.New("\"setup\" is required but was not present")
+		errs.add(missingField("jobs"))
 	}
 	// check if version (a required property) was received
 	if !versionReceived {
-		return errors.New("\"version\" is required but was not present")
+		errs.add(missingField("version"))
 	}
 	// check if workflows (a required property) was received
 	if !workflowsReceived {
-		return errors.New("\"workflows\" is required but was not present")
+		errs.add(missingField("workflows"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *CircleCIConfigSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *CircleCIConfigSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // DockerExecutorSchema a json representation of the docker executor schema to be converted to yaml.
 type DockerExecutorSchema struct {
 	Docker        []*DockerImageSchema `json:"docker"`
-	ResourceClass string               `json:"resource_class"`
+	ResourceClass string               `json:"resource_class,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerExecutorSchema) MarshalJSON() ([]byte, error) {
@@ -354,68 +628,76 @@ func (r *DockerExecutorSchema) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
-	// "ResourceClass" field is required
-	// only required object types supported for marshal checking (for now)
 	// Marshal the "resource_class" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"resource_class\": ")
-	if tmp, err := json.Marshal(r.ResourceClass); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.ResourceClass != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"resource_class\": ")
+		if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerExecutorSchema) UnmarshalJSON(b []byte) error {
 	dockerReceived := false
-	resource_classReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "docker":
-			if err := json.Unmarshal([]byte(v), &r.Docker); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Docker); err != nil {
+				errs.addAt(k, err)
 			}
 			dockerReceived = true
 		case "resource_class":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
 			}
-			resource_classReceived = true
+		default:
+			errs.add(unknownField(k, "docker", "resource_class"))
 		}
 	}
 	// check if docker (a required property) was received
 	if !dockerReceived {
-		return errors.New("\"docker\" is required but was not present")
+		errs.add(missingField("docker"))
 	}
-	// check if resource_class (a required property) was received
-	if !resource_classReceived {
-		return errors.New("\"resource_class\" is required but was not present")
-	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *DockerExecutorSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerExecutorSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // DockerImageSchema
 type DockerImageSchema struct {
-	Auth        *DockerAuth       `json:"auth,omitempty"`
-	AwsAuth     *DockerAuthAWS    `json:"aws_auth,omitempty"`
-	Command     []string          `json:"command,omitempty"`
-	Entrypoint  []string          `json:"entrypoint,omitempty"`
-	Environment map[string]string `json:"environment,omitempty"`
-	Image       string            `json:"image"`
-	Name        string            `json:"name,omitempty"`
-	User        string            `json:"user,omitempty"`
+	Auth        *DockerAuth    `json:"auth,omitempty"`
+	AwsAuth     *DockerAuthAWS `json:"aws_auth,omitempty"`
+	Command     StringOrList   `json:"command,omitempty"`
+	Entrypoint  StringOrList   `json:"entrypoint,omitempty"`
+	Environment Environment    `json:"environment,omitempty"`
+	Image       string         `json:"image"`
+	Name        string         `json:"name,omitempty"`
+	User        string         `json:"user,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *DockerImageSchema) MarshalJSON() ([]byte, error) {
@@ -423,60 +705,70 @@ func (r *DockerImageSchema) MarshalJSON() ([]byte, error) {
 	buf.WriteString("{")
 	comma := false
 	// Marshal the "auth" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"auth\": ")
-	if tmp, err := json.Marshal(r.Auth); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Auth != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"auth\": ")
+		if tmp, err := json.Marshal(r.Auth); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "aws_auth" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"aws_auth\": ")
-	if tmp, err := json.Marshal(r.AwsAuth); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.AwsAuth != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"aws_auth\": ")
+		if tmp, err := json.Marshal(r.AwsAuth); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "command" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"command\": ")
-	if tmp, err := json.Marshal(r.Command); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Command) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"command\": ")
+		if tmp, err := json.Marshal(r.Command); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "entrypoint" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"entrypoint\": ")
-	if tmp, err := json.Marshal(r.Entrypoint); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Entrypoint) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"entrypoint\": ")
+		if tmp, err := json.Marshal(r.Entrypoint); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "environment" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"environment\": ")
-	if tmp, err := json.Marshal(r.Environment); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Environment) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"environment\": ")
+		if tmp, err := json.Marshal(r.Environment); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "Image" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "image" field
@@ -491,31 +783,34 @@ func (r *DockerImageSchema) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "name" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"name\": ")
-	if tmp, err := json.Marshal(r.Name); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// Marshal the "user" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"user\": ")
-	if tmp, err := json.Marshal(r.User); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.User != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"user\": ")
+		if tmp, err := json.Marshal(r.User); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *DockerImageSchema) UnmarshalJSON(b []byte) error {
@@ -524,66 +819,113 @@ func (r *DockerImageSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "auth":
-			if err := json.Unmarshal([]byte(v), &r.Auth); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Auth); err != nil {
+				errs.addAt(k, err)
 			}
 		case "aws_auth":
-			if err := json.Unmarshal([]byte(v), &r.AwsAuth); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.AwsAuth); err != nil {
+				errs.addAt(k, err)
 			}
 		case "command":
-			if err := json.Unmarshal([]byte(v), &r.Command); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Command); err != nil {
+				errs.addAt(k, err)
 			}
 		case "entrypoint":
-			if err := json.Unmarshal([]byte(v), &r.Entrypoint); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Entrypoint); err != nil {
+				errs.addAt(k, err)
 			}
 		case "environment":
-			if err := json.Unmarshal([]byte(v), &r.Environment); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Environment); err != nil {
+				errs.addAt(k, err)
 			}
 		case "image":
-			if err := json.Unmarshal([]byte(v), &r.Image); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Image); err != nil {
+				errs.addAt(k, err)
 			}
 			imageReceived = true
 		case "name":
-			if err := json.Unmarshal([]byte(v), &r.Name); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
 			}
 		case "user":
-			if err := json.Unmarshal([]byte(v), &r.User); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.User); err != nil {
+				errs.addAt(k, err)
 			}
+		default:
+			errs.add(unknownField(k, "auth", "aws_auth", "command", "entrypoint", "environment", "image", "name", "user"))
 		}
 	}
 	// check if image (a required property) was received
 	if !imageReceived {
-		return errors.New("\"image\" is required but was not present")
+		errs.add(missingField("image"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
 }
 
-// JobSchema
-type JobSchema struct {
+func (r *DockerImageSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DockerImageSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// ExecutorReferenceSchema a reference to a reusable executor, written either as its name or as an object holding its name and the arguments passed to it.
+type ExecutorReferenceSchema struct {
+	// AdditionalProperties the arguments passed to the parameters of the executor.
 	AdditionalProperties map[string]interface{} `json:"-,omitempty"`
+
+	// The name of the executor, e.g. "go" or, for an executor of an orb, "node/default".
+	Name string `json:"name"`
+
+	unknownFields
+	sourceLayout
 }
 
-func (r *JobSchema) MarshalJSON() ([]byte, error) {
+func (r *ExecutorReferenceSchema) MarshalJSON() ([]byte, error) {
+	// without arguments, the reference is written in its short form
+	if len(r.AdditionalProperties) == 0 {
+		return json.Marshal(r.Name)
+	}
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal any additional Properties
-	for k, v := range r.AdditionalProperties {
+	// "Name" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "name" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"name\": ")
+	if tmp, err := json.Marshal(r.Name); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal any additional Properties, sorted by key
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.AdditionalProperties[k]
 		if comma {
 			buf.WriteString(",")
 		}
-		buf.WriteString(fmt.Sprintf("\"%s\":", k))
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
 		if tmp, err := json.Marshal(v); err != nil {
 			return nil, err
 		} else {
@@ -593,23 +935,36 @@ func (r *JobSchema) MarshalJSON() ([]byte, error) {
 	}
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
-func (r *JobSchema) UnmarshalJSON(b []byte) error {
+func (r *ExecutorReferenceSchema) UnmarshalJSON(b []byte) error {
+	// the short form is the name of the executor
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		r.Name = name
+		return nil
+	}
+	nameReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "name":
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
+			}
+			nameReceived = true
 		default:
 			// an additional "interface{}" value
 			var additionalValue interface{}
-			if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-				return err // invalid additionalProperty
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
 			if r.AdditionalProperties == nil {
 				r.AdditionalProperties = make(map[string]interface{})
@@ -617,141 +972,1102 @@ func (r *JobSchema) UnmarshalJSON(b []byte) error {
 			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	return nil
+	// check if name (a required property) was received
+	if !nameReceived {
+		errs.add(missingField("name"))
+	}
+	return r.keepUnknownFields(errs.err())
 }
 
-// JobStepsSchema
-type JobStepsSchema struct {
-	Steps []interface{} `json:"steps"`
+func (r *ExecutorReferenceSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
 }
 
-func (r *JobStepsSchema) MarshalJSON() ([]byte, error) {
+func (r *ExecutorReferenceSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// ExecutorSchema json schema for the reusable executors of a config, keyed by name.
+type ExecutorSchema struct {
+	AdditionalProperties map[string]*ExecutorSchemaItem `json:"-,omitempty"`
+
+	// Order the order in which the keys of AdditionalProperties appeared in the decoded source.
+	// Keys missing from Order are marshalled after it, sorted.
+	Order []string `json:"-"`
+
+	unknownFields
+}
+
+func (r *ExecutorSchema) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// "Steps" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "steps" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal any additional Properties, in source order
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
 	}
-	buf.WriteString("\"steps\": ")
-	if tmp, err := json.Marshal(r.Steps); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	for _, k := range orderKeys(keys, r.Order) {
+		v := r.AdditionalProperties[k]
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
 	rv := buf.Bytes()
 	return rv, nil
 }
 
-func (r *JobStepsSchema) UnmarshalJSON(b []byte) error {
-	stepsReceived := false
+func (r *ExecutorSchema) UnmarshalJSON(b []byte) error {
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.Order = objectKeys(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
-		case "steps":
-			if err := json.Unmarshal([]byte(v), &r.Steps); err != nil {
-				return err
+		default:
+			// an additional "*ExecutorSchemaItem" value
+			var additionalValue *ExecutorSchemaItem
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
-			stepsReceived = true
+			if r.AdditionalProperties == nil {
+				r.AdditionalProperties = make(map[string]*ExecutorSchemaItem)
+			}
+			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	// check if steps (a required property) was received
-	if !stepsReceived {
-		return errors.New("\"steps\" is required but was not present")
-	}
-	return nil
+	return r.keepUnknownFields(errs.err())
 }
 
-// MacOSExecutorSchema a json representation of the macOS executor schema to be converted to yaml.
-type MacOSExecutorSchema struct {
-	Macos         *Macos `json:"macos"`
-	ResourceClass string `json:"resource_class"`
+func (r *ExecutorSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
 }
 
-func (r *MacOSExecutorSchema) MarshalJSON() ([]byte, error) {
+func (r *ExecutorSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// ExecutorSchemaItem a reusable executor.
+//
+// Exactly one of Docker, Machine, MacOS and Windows is set, depending on the execution environment the executor declares.
+// A "machine" executor is a Windows executor if its image or resource class is a Windows one.
+type ExecutorSchemaItem struct {
+	Description string           `json:"description,omitempty"`
+	Parameters  *ParameterSchema `json:"parameters,omitempty"`
+
+	Docker  *DockerExecutorSchema  `json:"-"`
+	Machine *MachineExecutorSchema `json:"-"`
+	MacOS   *MacOSExecutorSchema   `json:"-"`
+	Windows *WindowsExecutorSchema `json:"-"`
+
+	Environment      Environment `json:"environment,omitempty"`
+	Shell            string      `json:"shell,omitempty"`
+	WorkingDirectory string      `json:"working_directory,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+// executor returns the execution environment of r, or nil if none is set.
+func (r *ExecutorSchemaItem) executor() interface{} {
+	switch {
+	case r.Docker != nil:
+		return r.Docker
+	case r.Machine != nil:
+		return r.Machine
+	case r.MacOS != nil:
+		return r.MacOS
+	case r.Windows != nil:
+		return r.Windows
+	}
+	return nil
+}
+
+func (r *ExecutorSchemaItem) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// "Macos" field is required
-	if r.Macos == nil {
-		return nil, errors.New("macos is a required field")
-	}
-	// Marshal the "macos" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the "description" field
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"macos\": ")
-	if tmp, err := json.Marshal(r.Macos); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "parameters" field
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// "ResourceClass" field is required
-	// only required object types supported for marshal checking (for now)
-	// Marshal the "resource_class" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the fields of the execution environment, in the order it writes them
+	executor := r.executor()
+	if executor == nil {
+		return nil, errors.New("one of docker, machine, macos or windows is required")
 	}
-	buf.WriteString("\"resource_class\": ")
-	if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+	var err error
+	if comma, err = writeObjectFields(buf, comma, executor); err != nil {
 		return nil, err
-	} else {
-		buf.Write(tmp)
 	}
-	comma = true
-
-	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
-}
-
-func (r *MacOSExecutorSchema) UnmarshalJSON(b []byte) error {
-	macosReceived := false
-	resource_classReceived := false
+	// Marshal the "environment" field
+	if len(r.Environment) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"environment\": ")
+		if tmp, err := json.Marshal(r.Environment); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "shell" field, unless the Windows executor already did
+	if r.Shell != "" && r.Windows == nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"shell\": ")
+		if tmp, err := json.Marshal(r.Shell); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "working_directory" field
+	if r.WorkingDirectory != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"working_directory\": ")
+		if tmp, err := json.Marshal(r.WorkingDirectory); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *ExecutorSchemaItem) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// the fields of the execution environment, decoded once it is known
+	executor := make(map[string]json.RawMessage)
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "description":
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
+			}
+		case "parameters":
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
+			}
+		case "environment":
+			if err := unmarshalValue(v, &r.Environment); err != nil {
+				errs.addAt(k, err)
+			}
+		case "working_directory":
+			if err := unmarshalValue(v, &r.WorkingDirectory); err != nil {
+				errs.addAt(k, err)
+			}
+		case "docker", "machine", "macos", "resource_class", "shell":
+			executor[k] = v
+		default:
+			errs.add(unknownField(k, "description", "docker", "environment", "machine", "macos", "parameters", "resource_class", "shell", "working_directory"))
+		}
+	}
+	if err := r.unmarshalExecutor(executor); err != nil {
+		errs.add(err)
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+// unmarshalExecutor decodes the fields of the execution environment into the matching one of Docker, Machine, MacOS and Windows.
+func (r *ExecutorSchemaItem) unmarshalExecutor(fields map[string]json.RawMessage) error {
+	kinds := executorKinds(fields)
+	switch len(kinds) {
+	case 0:
+		return &InvalidValueError{Reason: `one of "docker", "machine" or "macos" is required`}
+	case 1:
+	default:
+		return conflictingKeys(kinds[0], kinds[1])
+	}
+
+	if kinds[0] != "machine" || !isWindowsExecutor(fields) {
+		if shell, ok := fields["shell"]; ok {
+			delete(fields, "shell")
+			if err := unmarshalValue(shell, &r.Shell); err != nil {
+				return locate(jsonPointer("shell"), err)
+			}
+		}
+	}
+	executor, err := decodeExecutor(kinds[0], fields)
+	switch e := executor.(type) {
+	case *DockerExecutorSchema:
+		r.Docker = e
+	case *MachineExecutorSchema:
+		r.Machine = e
+	case *MacOSExecutorSchema:
+		r.MacOS = e
+	case *WindowsExecutorSchema:
+		r.Windows = e
+	}
+	return err
+}
+
+func (r *ExecutorSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *ExecutorSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// InlineOrbSchema json schema for an orb defined inline, in the orbs section of a config.
+type InlineOrbSchema struct {
+	Commands    *CommandSchema         `json:"commands,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Display     *OrbDisplaySchema      `json:"display,omitempty"`
+	Examples    map[string]interface{} `json:"examples,omitempty"`
+	Executors   *ExecutorSchema        `json:"executors,omitempty"`
+	Jobs        *JobSchema             `json:"jobs,omitempty"`
+	Orbs        *OrbSchema             `json:"orbs,omitempty"`
+	Version     float64                `json:"version,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *InlineOrbSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "version" field
+	if r.Version != 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"version\": ")
+		if tmp, err := json.Marshal(r.Version); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "description" field
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "display" field
+	if r.Display != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"display\": ")
+		if tmp, err := json.Marshal(r.Display); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "orbs" field
+	if r.Orbs != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"orbs\": ")
+		if tmp, err := json.Marshal(r.Orbs); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "commands" field
+	if r.Commands != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"commands\": ")
+		if tmp, err := json.Marshal(r.Commands); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "executors" field
+	if r.Executors != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"executors\": ")
+		if tmp, err := json.Marshal(r.Executors); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "jobs" field
+	if r.Jobs != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"jobs\": ")
+		if tmp, err := json.Marshal(r.Jobs); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "examples" field
+	if len(r.Examples) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"examples\": ")
+		if tmp, err := json.Marshal(r.Examples); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *InlineOrbSchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "commands":
+			if err := unmarshalValue(v, &r.Commands); err != nil {
+				errs.addAt(k, err)
+			}
+		case "description":
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
+			}
+		case "display":
+			if err := unmarshalValue(v, &r.Display); err != nil {
+				errs.addAt(k, err)
+			}
+		case "examples":
+			if err := unmarshalValue(v, &r.Examples); err != nil {
+				errs.addAt(k, err)
+			}
+		case "executors":
+			if err := unmarshalValue(v, &r.Executors); err != nil {
+				errs.addAt(k, err)
+			}
+		case "jobs":
+			if err := unmarshalValue(v, &r.Jobs); err != nil {
+				errs.addAt(k, err)
+			}
+		case "orbs":
+			if err := unmarshalValue(v, &r.Orbs); err != nil {
+				errs.addAt(k, err)
+			}
+		case "version":
+			if err := unmarshalVersion(v, &r.Version); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "commands", "description", "display", "examples", "executors", "jobs", "orbs", "version"))
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *InlineOrbSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *InlineOrbSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// JobSchema json schema for the jobs of a config, keyed by name.
+type JobSchema struct {
+	AdditionalProperties map[string]*JobSchemaItem `json:"-,omitempty"`
+
+	// Order the order in which the keys of AdditionalProperties appeared in the decoded source.
+	// Keys missing from Order are marshalled after it, sorted.
+	Order []string `json:"-"`
+
+	unknownFields
+}
+
+func (r *JobSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal any additional Properties, in source order
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	for _, k := range orderKeys(keys, r.Order) {
+		v := r.AdditionalProperties[k]
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (r *JobSchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.Order = objectKeys(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		default:
+			// an additional "*JobSchemaItem" value
+			var additionalValue *JobSchemaItem
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
+			} else if additionalValue == nil {
+				// a job written with no body, as in "build:", has no steps
+				errs.addAt(k, missingField("steps"))
+			}
+			if r.AdditionalProperties == nil {
+				r.AdditionalProperties = make(map[string]*JobSchemaItem)
+			}
+			r.AdditionalProperties[k] = additionalValue
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *JobSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *JobSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// JobSchemaItem a job: the steps to run, and the execution environment to run them in.
+//
+// The execution environment is either declared inline, in which case exactly one of Docker, Machine, MacOS and Windows is set,
+// or is a reusable executor referred to by Executor.
+type JobSchemaItem struct {
+	Description string           `json:"description,omitempty"`
+	Parameters  *ParameterSchema `json:"parameters,omitempty"`
+
+	Docker   *DockerExecutorSchema    `json:"-"`
+	Machine  *MachineExecutorSchema   `json:"-"`
+	MacOS    *MacOSExecutorSchema     `json:"-"`
+	Windows  *WindowsExecutorSchema   `json:"-"`
+	Executor *ExecutorReferenceSchema `json:"executor,omitempty"`
+
+	// Branches the branches a version 2 job is run for when it is not part of a workflow.
+	Branches *Branches `json:"branches,omitempty"`
+
+	// CircleCIIPRanges whether the job runs from the IP ranges of CircleCI: a bool, or a parameter expression string.
+	CircleCIIPRanges interface{} `json:"circleci_ip_ranges,omitempty"`
+	Environment      Environment `json:"environment,omitempty"`
+
+	// Parallelism the number of instances of the job to run in parallel: an int, or a parameter expression string.
+	Parallelism interface{} `json:"parallelism,omitempty"`
+
+	// ResourceClass the resource class of the job. If the execution environment is declared inline, it is also set on it.
+	ResourceClass    string `json:"resource_class,omitempty"`
+	Shell            string `json:"shell,omitempty"`
+	Steps            Steps  `json:"steps"`
+	WorkingDirectory string `json:"working_directory,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+// executor returns the inline execution environment of r, or nil if none is set.
+func (r *JobSchemaItem) executor() interface{} {
+	switch {
+	case r.Docker != nil:
+		return r.Docker
+	case r.Machine != nil:
+		return r.Machine
+	case r.MacOS != nil:
+		return r.MacOS
+	case r.Windows != nil:
+		return r.Windows
+	}
+	return nil
+}
+
+func (r *JobSchemaItem) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "description" field
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "parameters" field
+	if r.Parameters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parameters\": ")
+		if tmp, err := json.Marshal(r.Parameters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the fields of the inline execution environment, in the order it writes them, or the "executor" field
+	executor := r.executor()
+	switch {
+	case executor != nil && r.Executor != nil:
+		return nil, errors.New("executor cannot be used together with an inline execution environment")
+	case executor != nil:
+		var err error
+		if comma, err = writeObjectFields(buf, comma, withResourceClass(executor, r.ResourceClass, r.Shell)); err != nil {
+			return nil, err
+		}
+	case r.Executor != nil:
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"executor\": ")
+		if tmp, err := json.Marshal(r.Executor); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	default:
+		return nil, errors.New("one of docker, machine, macos, windows or executor is required")
+	}
+	// Marshal the "branches" field
+	if r.Branches != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"branches\": ")
+		if tmp, err := json.Marshal(r.Branches); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "circleci_ip_ranges" field
+	if r.CircleCIIPRanges != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"circleci_ip_ranges\": ")
+		if tmp, err := json.Marshal(r.CircleCIIPRanges); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "environment" field
+	if len(r.Environment) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"environment\": ")
+		if tmp, err := json.Marshal(r.Environment); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "parallelism" field
+	if r.Parallelism != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"parallelism\": ")
+		if tmp, err := json.Marshal(r.Parallelism); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "resource_class" field, unless the inline execution environment already did
+	if r.ResourceClass != "" && executor == nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"resource_class\": ")
+		if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "shell" field, unless the Windows execution environment already did
+	if r.Shell != "" && r.Windows == nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"shell\": ")
+		if tmp, err := json.Marshal(r.Shell); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// "Steps" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "steps" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"steps\": ")
+	if tmp, err := json.Marshal(r.Steps); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "working_directory" field
+	if r.WorkingDirectory != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"working_directory\": ")
+		if tmp, err := json.Marshal(r.WorkingDirectory); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *JobSchemaItem) UnmarshalJSON(b []byte) error {
+	stepsReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// the fields of the inline execution environment, decoded once it is known
+	executor := make(map[string]json.RawMessage)
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "description":
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
+			}
+		case "parameters":
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
+			}
+		case "docker", "machine", "macos":
+			executor[k] = v
+		case "executor":
+			if err := unmarshalValue(v, &r.Executor); err != nil {
+				errs.addAt(k, err)
+			}
+		case "branches":
+			if err := unmarshalValue(v, &r.Branches); err != nil {
+				errs.addAt(k, err)
+			}
+		case "circleci_ip_ranges":
+			if err := unmarshalBoolOrExpression(v, &r.CircleCIIPRanges); err != nil {
+				errs.addAt(k, err)
+			}
+		case "environment":
+			if err := unmarshalValue(v, &r.Environment); err != nil {
+				errs.addAt(k, err)
+			}
+		case "parallelism":
+			if err := unmarshalIntOrExpression(v, &r.Parallelism, 1, math.MaxInt); err != nil {
+				errs.addAt(k, err)
+			}
+		case "resource_class":
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
+			}
+		case "shell":
+			if err := unmarshalValue(v, &r.Shell); err != nil {
+				errs.addAt(k, err)
+			}
+		case "steps":
+			if err := unmarshalValue(v, &r.Steps); err != nil {
+				errs.addAt(k, err)
+			}
+			stepsReceived = true
+		case "working_directory":
+			if err := unmarshalValue(v, &r.WorkingDirectory); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "branches", "circleci_ip_ranges", "description", "docker", "environment", "executor", "machine", "macos", "parallelism", "parameters", "resource_class", "shell", "steps", "working_directory"))
+		}
+	}
+	// check if steps (a required property) was received
+	if !stepsReceived {
+		errs.add(missingField("steps"))
+	}
+	if err := r.unmarshalExecutor(executor); err != nil {
+		errs.add(err)
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+// unmarshalExecutor decodes the fields of the inline execution environment into the matching one of Docker, Machine, MacOS and Windows.
+// The resource class, and the shell of a Windows environment, are taken from the job.
+func (r *JobSchemaItem) unmarshalExecutor(fields map[string]json.RawMessage) error {
+	kinds := executorKinds(fields)
+	if r.Executor != nil {
+		kinds = append(kinds, "executor")
+	}
+	switch len(kinds) {
+	case 0:
+		return &InvalidValueError{Reason: `one of "docker", "machine", "macos" or "executor" is required`}
+	case 1:
+	default:
+		return conflictingKeys(kinds[0], kinds[1])
+	}
+	if kinds[0] == "executor" {
+		return nil
+	}
+
+	// resource_class and shell were already decoded, and any error reported, as fields of the job
+	if r.ResourceClass != "" {
+		fields["resource_class"], _ = json.Marshal(r.ResourceClass)
+	}
+	if r.Shell != "" && kinds[0] == "machine" && isWindowsExecutor(fields) {
+		fields["shell"], _ = json.Marshal(r.Shell)
+	}
+	executor, err := decodeExecutor(kinds[0], fields)
+	switch e := executor.(type) {
+	case *DockerExecutorSchema:
+		r.Docker = e
+	case *MachineExecutorSchema:
+		r.Machine = e
+	case *MacOSExecutorSchema:
+		r.MacOS = e
+	case *WindowsExecutorSchema:
+		r.Windows = e
+	}
+	return err
+}
+
+// unmarshalIntOrExpression decodes b, which is either an integer from min to max or a parameter expression, into *dst.
+func unmarshalIntOrExpression(b []byte, dst *interface{}, min, max int) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch p := v.(type) {
+	case float64:
+		if p != math.Trunc(p) {
+			return &TypeMismatchError{Expected: "integer", Actual: "number"}
+		}
+		if p < float64(min) {
+			return &InvalidValueError{Value: int(p), Reason: fmt.Sprintf("must be at least %d", min)}
+		}
+		if p > float64(max) {
+			return &InvalidValueError{Value: int(p), Reason: fmt.Sprintf("must be at most %d", max)}
+		}
+		*dst = int(p)
+		return nil
+	case string:
+		if isParameterExpression(p) {
+			*dst = p
+			return nil
+		}
+	}
+	return &TypeMismatchError{Expected: "integer", Actual: jsonValueType(v)}
+}
+
+// unmarshalBoolOrExpression decodes b, which is either a boolean or a parameter expression, into *dst.
+//
+// It decodes every boolean field that may be set by a parameter, such as "background: << parameters.background >>".
+func unmarshalBoolOrExpression(b []byte, dst *interface{}) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch p := v.(type) {
+	case bool:
+		*dst = p
+		return nil
+	case string:
+		if isParameterExpression(p) {
+			*dst = p
+			return nil
+		}
+	}
+	return &TypeMismatchError{Expected: "boolean", Actual: jsonValueType(v)}
+}
+
+func (r *JobSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *JobSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// JobStepsSchema
+type JobStepsSchema struct {
+	Steps Steps `json:"steps"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *JobStepsSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Steps" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "steps" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"steps\": ")
+	if tmp, err := json.Marshal(r.Steps); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *JobStepsSchema) UnmarshalJSON(b []byte) error {
+	stepsReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "steps":
+			if err := unmarshalValue(v, &r.Steps); err != nil {
+				errs.addAt(k, err)
+			}
+			stepsReceived = true
+		default:
+			errs.add(unknownField(k, "steps"))
+		}
+	}
+	// check if steps (a required property) was received
+	if !stepsReceived {
+		errs.add(missingField("steps"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *JobStepsSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *JobStepsSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// MacOSExecutorSchema a json representation of the macOS executor schema to be converted to yaml.
+type MacOSExecutorSchema struct {
+	Macos         *Macos `json:"macos"`
+	ResourceClass string `json:"resource_class,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *MacOSExecutorSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Macos" field is required
+	if r.Macos == nil {
+		return nil, errors.New("macos is a required field")
+	}
+	// Marshal the "macos" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"macos\": ")
+	if tmp, err := json.Marshal(r.Macos); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "resource_class" field
+	if r.ResourceClass != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"resource_class\": ")
+		if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *MacOSExecutorSchema) UnmarshalJSON(b []byte) error {
+	macosReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "macos":
-			if err := json.Unmarshal([]byte(v), &r.Macos); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Macos); err != nil {
+				errs.addAt(k, err)
 			}
 			macosReceived = true
 		case "resource_class":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
 			}
-			resource_classReceived = true
+		default:
+			errs.add(unknownField(k, "macos", "resource_class"))
 		}
 	}
 	// check if macos (a required property) was received
 	if !macosReceived {
-		return errors.New("\"macos\" is required but was not present")
-	}
-	// check if resource_class (a required property) was received
-	if !resource_classReceived {
-		return errors.New("\"resource_class\" is required but was not present")
+		errs.add(missingField("macos"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *MacOSExecutorSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *MacOSExecutorSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // MachineExecutorSchema
 type MachineExecutorSchema struct {
 	Machine       *Machine `json:"machine"`
-	ResourceClass string   `json:"resource_class"`
+	ResourceClass string   `json:"resource_class,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *MachineExecutorSchema) MarshalJSON() ([]byte, error) {
@@ -762,25 +2078,370 @@ func (r *MachineExecutorSchema) MarshalJSON() ([]byte, error) {
 	if r.Machine == nil {
 		return nil, errors.New("machine is a required field")
 	}
-	// Marshal the "machine" field
-	if comma {
-		buf.WriteString(",")
+	// Marshal the "machine" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"machine\": ")
+	if tmp, err := json.Marshal(r.Machine); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "resource_class" field
+	if r.ResourceClass != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"resource_class\": ")
+		if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *MachineExecutorSchema) UnmarshalJSON(b []byte) error {
+	machineReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "machine":
+			if err := unmarshalValue(v, &r.Machine); err != nil {
+				errs.addAt(k, err)
+			}
+			machineReceived = true
+		case "resource_class":
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "machine", "resource_class"))
+		}
+	}
+	// check if machine (a required property) was received
+	if !machineReceived {
+		errs.add(missingField("machine"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *MachineExecutorSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *MachineExecutorSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// OrbDisplaySchema json schema for the links shown for an orb in the registry.
+type OrbDisplaySchema struct {
+	HomeURL   string `json:"home_url,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *OrbDisplaySchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "home_url" field
+	if r.HomeURL != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"home_url\": ")
+		if tmp, err := json.Marshal(r.HomeURL); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "source_url" field
+	if r.SourceURL != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"source_url\": ")
+		if tmp, err := json.Marshal(r.SourceURL); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *OrbDisplaySchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "home_url":
+			if err := unmarshalValue(v, &r.HomeURL); err != nil {
+				errs.addAt(k, err)
+			}
+		case "source_url":
+			if err := unmarshalValue(v, &r.SourceURL); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "home_url", "source_url"))
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *OrbDisplaySchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *OrbDisplaySchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// OrbSchema json schema for the orbs used by a config or an inline orb, keyed by alias.
+type OrbSchema struct {
+	AdditionalProperties map[string]*ConfigOrbImport `json:"-,omitempty"`
+
+	// Order the order in which the keys of AdditionalProperties appeared in the decoded source.
+	// Keys missing from Order are marshalled after it, sorted.
+	Order []string `json:"-"`
+
+	unknownFields
+}
+
+func (r *OrbSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal any additional Properties, in source order
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	for _, k := range orderKeys(keys, r.Order) {
+		v := r.AdditionalProperties[k]
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (r *OrbSchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.Order = objectKeys(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		default:
+			// an additional "*ConfigOrbImport" value
+			var additionalValue *ConfigOrbImport
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
+			}
+			if additionalValue != nil {
+				additionalValue.OrbAlias = k
+			}
+			if r.AdditionalProperties == nil {
+				r.AdditionalProperties = make(map[string]*ConfigOrbImport)
+			}
+			r.AdditionalProperties[k] = additionalValue
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *OrbSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *OrbSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// ParameterSchema the parameters declared by a job, command or executor, keyed by name.
+type ParameterSchema struct {
+	AdditionalProperties map[string]*ParameterSchemaItem `json:"-,omitempty"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *ParameterSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal any additional Properties, sorted by key
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.AdditionalProperties[k]
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *ParameterSchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		default:
+			// an additional "*ParameterSchemaItem" value
+			var additionalValue *ParameterSchemaItem
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
+			}
+			if r.AdditionalProperties == nil {
+				r.AdditionalProperties = make(map[string]*ParameterSchemaItem)
+			}
+			r.AdditionalProperties[k] = additionalValue
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *ParameterSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *ParameterSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// ParameterSchemaItem the declaration of a parameter of a job, command or executor.
+type ParameterSchemaItem struct {
+	// The value used when no argument is passed. A parameter without a default is required.
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+
+	// The allowed values of an "enum" parameter.
+	Enum []string `json:"enum,omitempty"`
+	Type string   `json:"type"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *ParameterSchemaItem) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "default" field
+	if r.Default != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"default\": ")
+		if tmp, err := json.Marshal(r.Default); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "description" field
+	if r.Description != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"description\": ")
+		if tmp, err := json.Marshal(r.Description); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	buf.WriteString("\"machine\": ")
-	if tmp, err := json.Marshal(r.Machine); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	// Marshal the "enum" field
+	if len(r.Enum) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"enum\": ")
+		if tmp, err := json.Marshal(r.Enum); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// "ResourceClass" field is required
+	// "Type" field is required
 	// only required object types supported for marshal checking (for now)
-	// Marshal the "resource_class" field
+	// Marshal the "type" field
 	if comma {
 		buf.WriteString(",")
 	}
-	buf.WriteString("\"resource_class\": ")
-	if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+	buf.WriteString("\"type\": ")
+	if tmp, err := json.Marshal(r.Type); err != nil {
 		return nil, err
 	} else {
 		buf.Write(tmp)
@@ -788,46 +2449,62 @@ func (r *MachineExecutorSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
-func (r *MachineExecutorSchema) UnmarshalJSON(b []byte) error {
-	machineReceived := false
-	resource_classReceived := false
+func (r *ParameterSchemaItem) UnmarshalJSON(b []byte) error {
+	typeReceived := false
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
-		case "machine":
-			if err := json.Unmarshal([]byte(v), &r.Machine); err != nil {
-				return err
+		case "default":
+			if err := unmarshalValue(v, &r.Default); err != nil {
+				errs.addAt(k, err)
 			}
-			machineReceived = true
-		case "resource_class":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+		case "description":
+			if err := unmarshalValue(v, &r.Description); err != nil {
+				errs.addAt(k, err)
 			}
-			resource_classReceived = true
+		case "enum":
+			if err := unmarshalValue(v, &r.Enum); err != nil {
+				errs.addAt(k, err)
+			}
+		case "type":
+			if err := unmarshalValue(v, &r.Type); err != nil {
+				errs.addAt(k, err)
+			}
+			typeReceived = true
+		default:
+			errs.add(unknownField(k, "default", "description", "enum", "type"))
 		}
 	}
-	// check if machine (a required property) was received
-	if !machineReceived {
-		return errors.New("\"machine\" is required but was not present")
+	// check if type (a required property) was received
+	if !typeReceived {
+		errs.add(missingField("type"))
 	}
-	// check if resource_class (a required property) was received
-	if !resource_classReceived {
-		return errors.New("\"resource_class\" is required but was not present")
-	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *ParameterSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *ParameterSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // PersistCommandSchema json schema for the persist command.
 type PersistCommandSchema struct {
 	PersistToWorkspace *PersistParameters `json:"persist_to_workspace"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *PersistCommandSchema) MarshalJSON() ([]byte, error) {
@@ -851,8 +2528,7 @@ func (r *PersistCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *PersistCommandSchema) UnmarshalJSON(b []byte) error {
@@ -861,38 +2537,64 @@ func (r *PersistCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "persist_to_workspace":
-			if err := json.Unmarshal([]byte(v), &r.PersistToWorkspace); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.PersistToWorkspace); err != nil {
+				errs.addAt(k, err)
 			}
 			persist_to_workspaceReceived = true
+		default:
+			errs.add(unknownField(k, "persist_to_workspace"))
 		}
 	}
 	// check if persist_to_workspace (a required property) was received
 	if !persist_to_workspaceReceived {
-		return errors.New("\"persist_to_workspace\" is required but was not present")
+		errs.add(missingField("persist_to_workspace"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *PersistCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *PersistCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // PipelineParameterSchema
 type PipelineParameterSchema struct {
 	AdditionalProperties map[string]*PipelineParameterSchemaItem `json:"-,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *PipelineParameterSchema) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal any additional Properties
-	for k, v := range r.AdditionalProperties {
+	// Marshal any additional Properties, sorted by key
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.AdditionalProperties[k]
 		if comma {
 			buf.WriteString(",")
 		}
-		buf.WriteString(fmt.Sprintf("\"%s\":", k))
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
 		if tmp, err := json.Marshal(v); err != nil {
 			return nil, err
 		} else {
@@ -902,8 +2604,7 @@ func (r *PipelineParameterSchema) MarshalJSON() ([]byte, error) {
 	}
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *PipelineParameterSchema) UnmarshalJSON(b []byte) error {
@@ -911,14 +2612,16 @@ func (r *PipelineParameterSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		default:
 			// an additional "*PipelineParameterSchemaItem" value
 			var additionalValue *PipelineParameterSchemaItem
-			if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-				return err // invalid additionalProperty
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
 			if r.AdditionalProperties == nil {
 				r.AdditionalProperties = make(map[string]*PipelineParameterSchemaItem)
@@ -926,7 +2629,15 @@ func (r *PipelineParameterSchema) UnmarshalJSON(b []byte) error {
 			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *PipelineParameterSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *PipelineParameterSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // PipelineParameterSchemaItem
@@ -934,6 +2645,9 @@ type PipelineParameterSchemaItem struct {
 	Default       interface{} `json:"default"`
 	Enum          []string    `json:"enum,omitempty"`
 	ParameterType string      `json:"parameterType"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *PipelineParameterSchemaItem) MarshalJSON() ([]byte, error) {
@@ -954,16 +2668,18 @@ func (r *PipelineParameterSchemaItem) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 	// Marshal the "enum" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"enum\": ")
-	if tmp, err := json.Marshal(r.Enum); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if len(r.Enum) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"enum\": ")
+		if tmp, err := json.Marshal(r.Enum); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 	// "ParameterType" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "parameterType" field
@@ -979,8 +2695,7 @@ func (r *PipelineParameterSchemaItem) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *PipelineParameterSchemaItem) UnmarshalJSON(b []byte) error {
@@ -990,39 +2705,54 @@ func (r *PipelineParameterSchemaItem) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "default":
-			if err := json.Unmarshal([]byte(v), &r.Default); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Default); err != nil {
+				errs.addAt(k, err)
 			}
 			defaultReceived = true
 		case "enum":
-			if err := json.Unmarshal([]byte(v), &r.Enum); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Enum); err != nil {
+				errs.addAt(k, err)
 			}
 		case "parameterType":
-			if err := json.Unmarshal([]byte(v), &r.ParameterType); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.ParameterType); err != nil {
+				errs.addAt(k, err)
 			}
 			parameterTypeReceived = true
+		default:
+			errs.add(unknownField(k, "default", "enum", "parameterType"))
 		}
 	}
 	// check if default (a required property) was received
 	if !defaultReceived {
-		return errors.New("\"default\" is required but was not present")
+		errs.add(missingField("default"))
 	}
 	// check if parameterType (a required property) was received
 	if !parameterTypeReceived {
-		return errors.New("\"parameterType\" is required but was not present")
+		errs.add(missingField("parameterType"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *PipelineParameterSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *PipelineParameterSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // RestoreCacheCommandSchema json schema for the restorecache command.
 type RestoreCacheCommandSchema struct {
 	RestoreCache *RestoreCacheParameters `json:"restore_cache"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *RestoreCacheCommandSchema) MarshalJSON() ([]byte, error) {
@@ -1046,8 +2776,7 @@ func (r *RestoreCacheCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *RestoreCacheCommandSchema) UnmarshalJSON(b []byte) error {
@@ -1056,26 +2785,41 @@ func (r *RestoreCacheCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "restore_cache":
-			if err := json.Unmarshal([]byte(v), &r.RestoreCache); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.RestoreCache); err != nil {
+				errs.addAt(k, err)
 			}
 			restore_cacheReceived = true
+		default:
+			errs.add(unknownField(k, "restore_cache"))
 		}
 	}
 	// check if restore_cache (a required property) was received
 	if !restore_cacheReceived {
-		return errors.New("\"restore_cache\" is required but was not present")
+		errs.add(missingField("restore_cache"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *RestoreCacheCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *RestoreCacheCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // RunCommandSchema json schema for the run command.
 type RunCommandSchema struct {
 	Run *RunParameters `json:"run"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *RunCommandSchema) MarshalJSON() ([]byte, error) {
@@ -1099,8 +2843,7 @@ func (r *RunCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *RunCommandSchema) UnmarshalJSON(b []byte) error {
@@ -1109,26 +2852,41 @@ func (r *RunCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "run":
-			if err := json.Unmarshal([]byte(v), &r.Run); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Run); err != nil {
+				errs.addAt(k, err)
 			}
 			runReceived = true
+		default:
+			errs.add(unknownField(k, "run"))
 		}
 	}
 	// check if run (a required property) was received
 	if !runReceived {
-		return errors.New("\"run\" is required but was not present")
+		errs.add(missingField("run"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *RunCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *RunCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // SaveCacheCommandSchema json schema for the savecache command.
 type SaveCacheCommandSchema struct {
 	SaveCache *SaveCacheParameters `json:"save_cache"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *SaveCacheCommandSchema) MarshalJSON() ([]byte, error) {
@@ -1152,8 +2910,7 @@ func (r *SaveCacheCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *SaveCacheCommandSchema) UnmarshalJSON(b []byte) error {
@@ -1162,26 +2919,41 @@ func (r *SaveCacheCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "save_cache":
-			if err := json.Unmarshal([]byte(v), &r.SaveCache); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.SaveCache); err != nil {
+				errs.addAt(k, err)
 			}
 			save_cacheReceived = true
+		default:
+			errs.add(unknownField(k, "save_cache"))
 		}
 	}
 	// check if save_cache (a required property) was received
 	if !save_cacheReceived {
-		return errors.New("\"save_cache\" is required but was not present")
+		errs.add(missingField("save_cache"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *SaveCacheCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *SaveCacheCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // SetupRemoteDockerCommandSchema json schema for the setupremotedocker command.
 type SetupRemoteDockerCommandSchema struct {
 	SetupRemoteDocker *SetupRemoteDockerParameters `json:"setup_remote_docker"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *SetupRemoteDockerCommandSchema) MarshalJSON() ([]byte, error) {
@@ -1205,8 +2977,7 @@ func (r *SetupRemoteDockerCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *SetupRemoteDockerCommandSchema) UnmarshalJSON(b []byte) error {
@@ -1215,26 +2986,41 @@ func (r *SetupRemoteDockerCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "setup_remote_docker":
-			if err := json.Unmarshal([]byte(v), &r.SetupRemoteDocker); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.SetupRemoteDocker); err != nil {
+				errs.addAt(k, err)
 			}
 			setup_remote_dockerReceived = true
+		default:
+			errs.add(unknownField(k, "setup_remote_docker"))
 		}
 	}
 	// check if setup_remote_docker (a required property) was received
 	if !setup_remote_dockerReceived {
-		return errors.New("\"setup_remote_docker\" is required but was not present")
+		errs.add(missingField("setup_remote_docker"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *SetupRemoteDockerCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *SetupRemoteDockerCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // StoreArtifactsCommandSchema json schema for the storeartifacts command.
 type StoreArtifactsCommandSchema struct {
 	StoreArtifacts *StoreArtifactsParameters `json:"store_artifacts"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *StoreArtifactsCommandSchema) MarshalJSON() ([]byte, error) {
@@ -1258,8 +3044,7 @@ func (r *StoreArtifactsCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *StoreArtifactsCommandSchema) UnmarshalJSON(b []byte) error {
@@ -1268,26 +3053,41 @@ func (r *StoreArtifactsCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "store_artifacts":
-			if err := json.Unmarshal([]byte(v), &r.StoreArtifacts); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.StoreArtifacts); err != nil {
+				errs.addAt(k, err)
 			}
 			store_artifactsReceived = true
+		default:
+			errs.add(unknownField(k, "store_artifacts"))
 		}
 	}
 	// check if store_artifacts (a required property) was received
 	if !store_artifactsReceived {
-		return errors.New("\"store_artifacts\" is required but was not present")
+		errs.add(missingField("store_artifacts"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *StoreArtifactsCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StoreArtifactsCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // StoreTestResultsCommandSchema json schema for the storetestresults command.
 type StoreTestResultsCommandSchema struct {
 	StoreTestResults *StoreTestResultsParameters `json:"store_test_results"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *StoreTestResultsCommandSchema) MarshalJSON() ([]byte, error) {
@@ -1311,8 +3111,7 @@ func (r *StoreTestResultsCommandSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *StoreTestResultsCommandSchema) UnmarshalJSON(b []byte) error {
@@ -1321,28 +3120,43 @@ func (r *StoreTestResultsCommandSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "store_test_results":
-			if err := json.Unmarshal([]byte(v), &r.StoreTestResults); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.StoreTestResults); err != nil {
+				errs.addAt(k, err)
 			}
 			store_test_resultsReceived = true
+		default:
+			errs.add(unknownField(k, "store_test_results"))
 		}
 	}
 	// check if store_test_results (a required property) was received
 	if !store_test_resultsReceived {
-		return errors.New("\"store_test_results\" is required but was not present")
+		errs.add(missingField("store_test_results"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *StoreTestResultsCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *StoreTestResultsCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // WindowsExecutorSchema a json representation of the Windows executor schema to be converted to yaml.
 type WindowsExecutorSchema struct {
 	Machine       *Machine `json:"machine"`
-	ResourceClass string   `json:"resource_class"`
-	Shell         string   `json:"shell"`
+	ResourceClass string   `json:"resource_class,omitempty"`
+	Shell         string   `json:"shell,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *WindowsExecutorSchema) MarshalJSON() ([]byte, error) {
@@ -1364,105 +3178,364 @@ func (r *WindowsExecutorSchema) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
-	// "ResourceClass" field is required
-	// only required object types supported for marshal checking (for now)
 	// Marshal the "resource_class" field
-	if comma {
-		buf.WriteString(",")
-	}
-	buf.WriteString("\"resource_class\": ")
-	if tmp, err := json.Marshal(r.ResourceClass); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	if r.ResourceClass != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"resource_class\": ")
+		if tmp, err := json.Marshal(r.ResourceClass); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
-	// "Shell" field is required
-	// only required object types supported for marshal checking (for now)
 	// Marshal the "shell" field
-	if comma {
-		buf.WriteString(",")
+	if r.Shell != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"shell\": ")
+		if tmp, err := json.Marshal(r.Shell); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *WindowsExecutorSchema) UnmarshalJSON(b []byte) error {
+	machineReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "machine":
+			if err := unmarshalValue(v, &r.Machine); err != nil {
+				errs.addAt(k, err)
+			}
+			machineReceived = true
+		case "resource_class":
+			if err := unmarshalValue(v, &r.ResourceClass); err != nil {
+				errs.addAt(k, err)
+			}
+		case "shell":
+			if err := unmarshalValue(v, &r.Shell); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "machine", "resource_class", "shell"))
+		}
+	}
+	// check if machine (a required property) was received
+	if !machineReceived {
+		errs.add(missingField("machine"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WindowsExecutorSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WindowsExecutorSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// WorkflowFilterSchema
+type WorkflowFilterSchema struct {
+	// A map defining rules for execution on specific branches
+	Branches *Branches `json:"branches,omitempty"`
+
+	// A map defining rules for execution on specific tags
+	Tags *Tags `json:"tags,omitempty"`
+
+	unknownFields
+}
+
+func (r *WorkflowFilterSchema) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "branches":
+			if err := unmarshalValue(v, &r.Branches); err != nil {
+				errs.addAt(k, err)
+			}
+		case "tags":
+			if err := unmarshalValue(v, &r.Tags); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "branches", "tags"))
+		}
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WorkflowFilterSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WorkflowFilterSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// WorkflowJobSchema a job of a workflow, keyed by its name. A job without parameters is written as its bare name, and has a nil item.
+type WorkflowJobSchema struct {
+	AdditionalProperties map[string]*WorkflowJobSchemaItem `json:"-,omitempty"`
+
+	// Order the order in which the keys of AdditionalProperties appeared in the decoded source.
+	// Keys missing from Order are marshalled after it, sorted.
+	Order []string `json:"-"`
+
+	unknownFields
+}
+
+func (r *WorkflowJobSchema) MarshalJSON() ([]byte, error) {
+	// a single job without parameters is written as its bare name
+	if len(r.AdditionalProperties) == 1 {
+		for k, v := range r.AdditionalProperties {
+			if v == nil {
+				return json.Marshal(k)
+			}
+		}
+	}
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal any additional Properties, in source order
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
 	}
-	buf.WriteString("\"shell\": ")
-	if tmp, err := json.Marshal(r.Shell); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+	for _, k := range orderKeys(keys, r.Order) {
+		v := r.AdditionalProperties[k]
+		if comma {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
 	}
-	comma = true
 
 	buf.WriteString("}")
 	rv := buf.Bytes()
 	return rv, nil
 }
 
-func (r *WindowsExecutorSchema) UnmarshalJSON(b []byte) error {
-	machineReceived := false
-	resource_classReceived := false
-	shellReceived := false
+func (r *WorkflowJobSchema) UnmarshalJSON(b []byte) error {
+	// a bare job name is shorthand for the job without parameters
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		r.AdditionalProperties = map[string]*WorkflowJobSchemaItem{name: nil}
+		r.Order = []string{name}
+		return nil
+	}
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.Order = objectKeys(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
-		case "machine":
-			if err := json.Unmarshal([]byte(v), &r.Machine); err != nil {
-				return err
-			}
-			machineReceived = true
-		case "resource_class":
-			if err := json.Unmarshal([]byte(v), &r.ResourceClass); err != nil {
-				return err
+		default:
+			// an additional "*WorkflowJobSchemaItem" value
+			var additionalValue *WorkflowJobSchemaItem
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
-			resource_classReceived = true
-		case "shell":
-			if err := json.Unmarshal([]byte(v), &r.Shell); err != nil {
-				return err
+			if r.AdditionalProperties == nil {
+				r.AdditionalProperties = make(map[string]*WorkflowJobSchemaItem)
 			}
-			shellReceived = true
+			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	// check if machine (a required property) was received
-	if !machineReceived {
-		return errors.New("\"machine\" is required but was not present")
-	}
-	// check if resource_class (a required property) was received
-	if !resource_classReceived {
-		return errors.New("\"resource_class\" is required but was not present")
-	}
-	// check if shell (a required property) was received
-	if !shellReceived {
-		return errors.New("\"shell\" is required but was not present")
-	}
-	return nil
+	return r.keepUnknownFields(errs.err())
 }
 
-// WorkflowFilterSchema
-type WorkflowFilterSchema struct {
-	// A map defining rules for execution on specific branches
-	Branches *Branches `json:"branches,omitempty"`
+func (r *WorkflowJobSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
 
-	// A map defining rules for execution on specific tags
-	Tags *Tags `json:"tags,omitempty"`
+func (r *WorkflowJobSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
-// WorkflowJobSchema
-type WorkflowJobSchema struct {
-	AdditionalProperties map[string]*WorkflowJobSchemaItem `json:"-,omitempty"`
+// WorkflowJobSchemaItem
+type WorkflowJobSchemaItem struct {
+	// AdditionalProperties the arguments passed to the parameters of the job.
+	AdditionalProperties map[string]interface{} `json:"-,omitempty"`
+
+	Context StringOrList          `json:"context,omitempty"`
+	Filters *WorkflowFilterSchema `json:"filters,omitempty"`
+
+	// JobType the "type" of the job, "approval" for a job that pauses the workflow until it is approved.
+	// Such a job is not defined under jobs; "jobType" is accepted as an alias of "type" when decoding.
+	JobType string `json:"type,omitempty"`
+
+	Matrix *WorkflowMatrixSchema `json:"matrix,omitempty"`
+
+	// The name the job runs under in the workflow, to run the same job more than once.
+	Name      string       `json:"name,omitempty"`
+	PostSteps Steps        `json:"post-steps,omitempty"`
+	PreSteps  Steps        `json:"pre-steps,omitempty"`
+	Requires  StringOrList `json:"requires,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
-func (r *WorkflowJobSchema) MarshalJSON() ([]byte, error) {
+func (r *WorkflowJobSchemaItem) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal any additional Properties
-	for k, v := range r.AdditionalProperties {
+	// Marshal the "context" field
+	if len(r.Context) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"context\": ")
+		if tmp, err := json.Marshal(r.Context); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "filters" field
+	if r.Filters != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"filters\": ")
+		if tmp, err := json.Marshal(r.Filters); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "type" field
+	if r.JobType != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"type\": ")
+		if tmp, err := json.Marshal(r.JobType); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "matrix" field
+	if r.Matrix != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"matrix\": ")
+		if tmp, err := json.Marshal(r.Matrix); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "name" field
+	if r.Name != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"name\": ")
+		if tmp, err := json.Marshal(r.Name); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "post-steps" field
+	if len(r.PostSteps) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"post-steps\": ")
+		if tmp, err := json.Marshal(r.PostSteps); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "pre-steps" field
+	if len(r.PreSteps) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"pre-steps\": ")
+		if tmp, err := json.Marshal(r.PreSteps); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "requires" field
+	if len(r.Requires) > 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"requires\": ")
+		if tmp, err := json.Marshal(r.Requires); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal any additional Properties, sorted by key
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.AdditionalProperties[k]
 		if comma {
 			buf.WriteString(",")
 		}
-		buf.WriteString(fmt.Sprintf("\"%s\":", k))
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
 		if tmp, err := json.Marshal(v); err != nil {
 			return nil, err
 		} else {
@@ -1472,51 +3545,118 @@ func (r *WorkflowJobSchema) MarshalJSON() ([]byte, error) {
 	}
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
-func (r *WorkflowJobSchema) UnmarshalJSON(b []byte) error {
+func (r *WorkflowJobSchemaItem) UnmarshalJSON(b []byte) error {
 	var jsonMap map[string]json.RawMessage
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "context":
+			if err := unmarshalValue(v, &r.Context); err != nil {
+				errs.addAt(k, err)
+			}
+		case "filters":
+			if err := unmarshalValue(v, &r.Filters); err != nil {
+				errs.addAt(k, err)
+			}
+		case "type", "jobType":
+			if err := unmarshalValue(v, &r.JobType); err != nil {
+				errs.addAt(k, err)
+			}
+		case "matrix":
+			if err := unmarshalValue(v, &r.Matrix); err != nil {
+				errs.addAt(k, err)
+			}
+		case "name":
+			if err := unmarshalValue(v, &r.Name); err != nil {
+				errs.addAt(k, err)
+			}
+		case "post-steps":
+			if err := unmarshalValue(v, &r.PostSteps); err != nil {
+				errs.addAt(k, err)
+			}
+		case "pre-steps":
+			if err := unmarshalValue(v, &r.PreSteps); err != nil {
+				errs.addAt(k, err)
+			}
+		case "requires":
+			if err := unmarshalValue(v, &r.Requires); err != nil {
+				errs.addAt(k, err)
+			}
 		default:
-			// an additional "*WorkflowJobSchemaItem" value
-			var additionalValue *WorkflowJobSchemaItem
-			if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-				return err // invalid additionalProperty
+			// an additional "interface{}" value
+			var additionalValue interface{}
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
 			if r.AdditionalProperties == nil {
-				r.AdditionalProperties = make(map[string]*WorkflowJobSchemaItem)
+				r.AdditionalProperties = make(map[string]interface{})
 			}
 			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
 }
 
-// WorkflowJobSchemaItem
-type WorkflowJobSchemaItem struct {
-	Context  []string              `json:"context,omitempty"`
-	Filters  *WorkflowFilterSchema `json:"filters,omitempty"`
-	JobType  string                `json:"jobType,omitempty"`
-	Matrix   *WorkflowMatrixSchema `json:"matrix,omitempty"`
-	Requires []string              `json:"requires,omitempty"`
+func (r *WorkflowJobSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WorkflowJobSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // WorkflowMatrixSchema a map of parameter names to every value the job should be called with.
 type WorkflowMatrixSchema struct {
-	Parameters map[string][]string `json:"parameters"`
+	// Alias the name other jobs require to wait for every job of the matrix. It defaults to the name of the job.
+	Alias string `json:"alias,omitempty"`
+
+	// Exclude the combinations of parameter values that are not run, each keyed by parameter name.
+	Exclude []map[string]interface{} `json:"exclude,omitempty"`
+
+	Parameters map[string][]interface{} `json:"parameters"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *WorkflowMatrixSchema) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
+	// Marshal the "alias" field
+	if r.Alias != "" {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"alias\": ")
+		if tmp, err := json.Marshal(r.Alias); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "exclude" field
+	if r.Exclude != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"exclude\": ")
+		if tmp, err := json.Marshal(r.Exclude); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
 	// "Parameters" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "parameters" field
@@ -1532,8 +3672,7 @@ func (r *WorkflowMatrixSchema) MarshalJSON() ([]byte, error) {
 	comma = true
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *WorkflowMatrixSchema) UnmarshalJSON(b []byte) error {
@@ -1542,38 +3681,90 @@ func (r *WorkflowMatrixSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "alias":
+			if err := unmarshalValue(v, &r.Alias); err != nil {
+				errs.addAt(k, err)
+			}
+		case "exclude":
+			if err := unmarshalValue(v, &r.Exclude); err != nil {
+				errs.addAt(k, err)
+			}
 		case "parameters":
-			if err := json.Unmarshal([]byte(v), &r.Parameters); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Parameters); err != nil {
+				errs.addAt(k, err)
 			}
 			parametersReceived = true
+		default:
+			errs.add(unknownField(k, "alias", "exclude", "parameters"))
 		}
 	}
 	// check if parameters (a required property) was received
 	if !parametersReceived {
-		return errors.New("\"parameters\" is required but was not present")
+		errs.add(missingField("parameters"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WorkflowMatrixSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WorkflowMatrixSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
 
 // WorkflowSchema
 type WorkflowSchema struct {
 	AdditionalProperties map[string]*WorkflowSchemaItem `json:"-,omitempty"`
+
+	// Version the version of the workflows section. It is required to be 2 by configs of version 2, and ignored by configs of version 2.1.
+	Version float64 `json:"version,omitempty"`
+
+	// Order the order in which the keys of AdditionalProperties appeared in the decoded source.
+	// Keys missing from Order are marshalled after it, sorted.
+	Order []string `json:"-"`
+
+	unknownFields
 }
 
 func (r *WorkflowSchema) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
-	// Marshal any additional Properties
-	for k, v := range r.AdditionalProperties {
+	// Marshal the "version" field
+	if r.Version != 0 {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"version\": ")
+		if tmp, err := json.Marshal(r.Version); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal any additional Properties, in source order
+	keys := make([]string, 0, len(r.AdditionalProperties))
+	for k := range r.AdditionalProperties {
+		keys = append(keys, k)
+	}
+	for _, k := range orderKeys(keys, r.Order) {
+		v := r.AdditionalProperties[k]
 		if comma {
 			buf.WriteString(",")
 		}
-		buf.WriteString(fmt.Sprintf("\"%s\":", k))
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
 		if tmp, err := json.Marshal(v); err != nil {
 			return nil, err
 		} else {
@@ -1592,14 +3783,20 @@ func (r *WorkflowSchema) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.Order = objectKeys(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "version":
+			if err := unmarshalVersion(v, &r.Version); err != nil {
+				errs.addAt(k, err)
+			}
 		default:
 			// an additional "*WorkflowSchemaItem" value
 			var additionalValue *WorkflowSchemaItem
-			if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-				return err // invalid additionalProperty
+			if err := unmarshalValue(v, &additionalValue); err != nil {
+				errs.addAt(k, err) // invalid additionalProperty
 			}
 			if r.AdditionalProperties == nil {
 				r.AdditionalProperties = make(map[string]*WorkflowSchemaItem)
@@ -1607,18 +3804,64 @@ func (r *WorkflowSchema) UnmarshalJSON(b []byte) error {
 			r.AdditionalProperties[k] = additionalValue
 		}
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WorkflowSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
 }
 
-// WorkflowSchemaItem
+func (r *WorkflowSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// WorkflowSchemaItem json schema for a workflow.
 type WorkflowSchemaItem struct {
 	Jobs []*WorkflowJobSchema `json:"jobs"`
+
+	// Triggers the events that run the workflow, besides a push to the repository.
+	Triggers []*Trigger `json:"triggers,omitempty"`
+
+	// Unless the workflow is run only if this condition does not hold.
+	Unless *LogicSchema `json:"unless,omitempty"`
+
+	// When the workflow is run only if this condition holds.
+	When *LogicSchema `json:"when,omitempty"`
+
+	unknownFields
+	sourceLayout
 }
 
 func (r *WorkflowSchemaItem) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
+	// Marshal the "when" field
+	if r.When != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"when\": ")
+		if tmp, err := json.Marshal(r.When); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
+	// Marshal the "unless" field
+	if r.Unless != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"unless\": ")
+		if tmp, err := json.Marshal(r.Unless); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
 	// "Jobs" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "jobs" field
@@ -1632,10 +3875,22 @@ func (r *WorkflowSchemaItem) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "triggers" field
+	if r.Triggers != nil {
+		if comma {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\"triggers\": ")
+		if tmp, err := json.Marshal(r.Triggers); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		comma = true
+	}
 
 	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return r.restoreLayout(buf.Bytes())
 }
 
 func (r *WorkflowSchemaItem) UnmarshalJSON(b []byte) error {
@@ -1644,19 +3899,43 @@ func (r *WorkflowSchemaItem) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &jsonMap); err != nil {
 		return err
 	}
+	r.setKeyOrder(b)
+	var errs Errors
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
 		case "jobs":
-			if err := json.Unmarshal([]byte(v), &r.Jobs); err != nil {
-				return err
+			if err := unmarshalValue(v, &r.Jobs); err != nil {
+				errs.addAt(k, err)
 			}
 			jobsReceived = true
+		case "triggers":
+			if err := unmarshalValue(v, &r.Triggers); err != nil {
+				errs.addAt(k, err)
+			}
+		case "unless":
+			if err := unmarshalValue(v, &r.Unless); err != nil {
+				errs.addAt(k, err)
+			}
+		case "when":
+			if err := unmarshalValue(v, &r.When); err != nil {
+				errs.addAt(k, err)
+			}
+		default:
+			errs.add(unknownField(k, "jobs", "triggers", "unless", "when"))
 		}
 	}
 	// check if jobs (a required property) was received
 	if !jobsReceived {
-		return errors.New("\"jobs\" is required but was not present")
+		errs.add(missingField("jobs"))
 	}
-	return nil
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WorkflowSchemaItem) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WorkflowSchemaItem) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
 }
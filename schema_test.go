@@ -0,0 +1,40 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalAdditionalPropertiesSorted(t *testing.T) {
+	t.Parallel()
+
+	job := &JobSchemaItem{Executor: &ExecutorReferenceSchema{Name: "go"}, Steps: Steps{&CheckoutCommandSchema{Checkout: new(CheckoutParameter)}}}
+	jobs := &JobSchema{AdditionalProperties: map[string]*JobSchemaItem{
+		"zeta":     job,
+		`say "hi"`: job,
+		"alpha":    job,
+		`back\`:    job,
+	}}
+	want := `{"alpha":{"executor":"go","steps":[{"checkout":{}}]},"back\\":{"executor":"go","steps":[{"checkout":{}}]},"say \"hi\"":{"executor":"go","steps":[{"checkout":{}}]},"zeta":{"executor":"go","steps":[{"checkout":{}}]}}`
+	for i := 0; i < 10; i++ {
+		b, err := json.Marshal(jobs)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		if string(b) != want {
+			t.Fatalf("json.Marshal() = %s, want %s", b, want)
+		}
+	}
+
+	var decoded JobSchema
+	b, _ := json.Marshal(jobs)
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if _, ok := decoded.AdditionalProperties[`say "hi"`]; !ok {
+		t.Errorf("escaped key was not decoded back: %v", decoded.AdditionalProperties)
+	}
+}
@@ -0,0 +1,653 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Step a step of a job or of a reusable command.
+//
+// A step is one of the built-in steps, such as *RunCommandSchema or *CheckoutCommandSchema, a conditional step
+// (*WhenCommandSchema, *UnlessCommandSchema), an invocation of a reusable command (*CustomCommandStep) or of a command of
+// an orb (*OrbCommandStep), the expansion of a steps parameter (*ParameterStep), or a nested list of steps (*StepListStep).
+type Step interface {
+	json.Marshaler
+
+	// StepName returns the name the step is invoked by, such as "run", "greet" or "node/install-packages".
+	StepName() string
+}
+
+func (r *AddSSHKeysCommandSchema) StepName() string        { return "add_ssh_keys" }
+func (r *AttachCommandSchema) StepName() string            { return "attach_workspace" }
+func (r *CheckoutCommandSchema) StepName() string          { return "checkout" }
+func (r *DeployCommandSchema) StepName() string            { return "deploy" }
+func (r *PersistCommandSchema) StepName() string           { return "persist_to_workspace" }
+func (r *RestoreCacheCommandSchema) StepName() string      { return "restore_cache" }
+func (r *RunCommandSchema) StepName() string               { return "run" }
+func (r *SaveCacheCommandSchema) StepName() string         { return "save_cache" }
+func (r *SetupRemoteDockerCommandSchema) StepName() string { return "setup_remote_docker" }
+func (r *StoreArtifactsCommandSchema) StepName() string    { return "store_artifacts" }
+func (r *StoreTestResultsCommandSchema) StepName() string  { return "store_test_results" }
+func (r *UnlessCommandSchema) StepName() string            { return "unless" }
+func (r *WhenCommandSchema) StepName() string              { return "when" }
+
+// builtinStepTypes returns a new, empty step for each built-in step name.
+var builtinStepTypes = map[string]func() Step{
+	"add_ssh_keys":         func() Step { return new(AddSSHKeysCommandSchema) },
+	"attach_workspace":     func() Step { return new(AttachCommandSchema) },
+	"checkout":             func() Step { return new(CheckoutCommandSchema) },
+	"deploy":               func() Step { return new(DeployCommandSchema) },
+	"persist_to_workspace": func() Step { return new(PersistCommandSchema) },
+	"restore_cache":        func() Step { return new(RestoreCacheCommandSchema) },
+	"run":                  func() Step { return new(RunCommandSchema) },
+	"save_cache":           func() Step { return new(SaveCacheCommandSchema) },
+	"setup_remote_docker":  func() Step { return new(SetupRemoteDockerCommandSchema) },
+	"store_artifacts":      func() Step { return new(StoreArtifactsCommandSchema) },
+	"store_test_results":   func() Step { return new(StoreTestResultsCommandSchema) },
+	"unless":               func() Step { return new(UnlessCommandSchema) },
+	"when":                 func() Step { return new(WhenCommandSchema) },
+}
+
+// Steps a list of steps, decoded into their concrete types. See Step.
+//
+// A step whose kind could not be determined is decoded as nil, so that every step keeps the index it has in the source.
+type Steps []Step
+
+func (r *Steps) UnmarshalJSON(b []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	var errs Errors
+	steps := make(Steps, len(items))
+	for i, item := range items {
+		step, err := decodeStep(item)
+		if err != nil {
+			errs.addAt(strconv.Itoa(i), err)
+		}
+		steps[i] = step
+	}
+	*r = steps
+	return errs.err()
+}
+
+// takeUnknownFields takes the unknown keys kept by the steps of r. See unknownFields.
+func (r Steps) takeUnknownFields() Errors {
+	var unknown Errors
+	for i, step := range r {
+		if h, ok := step.(unknownFieldHolder); ok {
+			if u := h.takeUnknownFields(); len(u) > 0 {
+				unknown.addAt(strconv.Itoa(i), u)
+			}
+		}
+	}
+	return unknown
+}
+
+func (r Steps) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *Steps) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// decodeStep decodes the JSON encoding of a single step into its concrete type.
+//
+// A step is written as an object with a single key, the name of the step, whose value holds its parameters or arguments.
+// Steps may also be written by their bare name, as in "checkout" or "greet", and the run step by its command alone, as in
+// `run: make test`. These short forms are normalised, so that "checkout" decodes as the step `checkout: {}` would, but a
+// built-in step written in a short form is encoded in it again as long as its parameters are unchanged.
+// The returned step is nil only if the kind of step could not be determined.
+func decodeStep(b []byte) (Step, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	src := b
+	var name string
+	short := false
+	switch s := v.(type) {
+	case string:
+		if isParameterExpression(s) {
+			return &ParameterStep{Expression: s}, nil
+		}
+		if _, ok := builtinStepTypes[s]; !ok {
+			return newInvocationStep(s, nil), nil
+		}
+		// the short form of a built-in step, as in "checkout", is the step with default parameters
+		name = s
+		b = shortStep(name)
+		short = true
+	case map[string]interface{}:
+		if len(s) != 1 {
+			return nil, &InvalidValueError{Reason: "a step must have exactly one key, the name of the step"}
+		}
+		for k := range s {
+			name = k
+		}
+		if name == "steps" {
+			return decodeStepList(s[name], b)
+		}
+		if _, ok := builtinStepTypes[name]; ok {
+			switch s[name].(type) {
+			case nil:
+				// "checkout:" with no value, as written in YAML, is the short form too
+				b = shortStep(name)
+				short = true
+			case string:
+				// the command of a run step
+				short = true
+			}
+		}
+	default:
+		return nil, &TypeMismatchError{Expected: "string or object", Actual: jsonValueType(v)}
+	}
+
+	if newStep, ok := builtinStepTypes[name]; ok {
+		// the step keeps its unknown keys, which Steps hands on to the object holding it
+		step := newStep()
+		if err := json.Unmarshal(b, step); err != nil {
+			return step, err
+		}
+		if short {
+			if long, err := json.Marshal(step); err == nil {
+				step.(interface{ setShortForm(short, long []byte) }).setShortForm(src, long)
+			}
+		}
+		return step, nil
+	}
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return nil, err
+	}
+	args := make(map[string]interface{})
+	if err := unmarshalValue(jsonMap[name], &args); err != nil {
+		return newInvocationStep(name, args), locate(jsonPointer(name), err)
+	}
+	return newInvocationStep(name, args), nil
+}
+
+// decodeStepList decodes b, the JSON encoding of a step written as `steps:` followed by v, as in
+// `steps: << parameters.after-deps >>`, into a *ParameterStep if v is a parameter expression, or into a *StepListStep.
+func decodeStepList(v interface{}, b []byte) (Step, error) {
+	if s, ok := v.(string); ok && isParameterExpression(s) {
+		return &ParameterStep{Expression: s, Keyed: true}, nil
+	}
+	if _, ok := v.([]interface{}); !ok {
+		return nil, &TypeMismatchError{Path: jsonPointer("steps"), Expected: "array or parameter expression", Actual: jsonValueType(v)}
+	}
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return nil, err
+	}
+	step := new(StepListStep)
+	if err := unmarshalValue(jsonMap["steps"], &step.Steps); err != nil {
+		return step, step.keepUnknownFields(locate(jsonPointer("steps"), err))
+	}
+	return step, nil
+}
+
+// shortStep returns the JSON encoding of the built-in step name with default parameters.
+func shortStep(name string) []byte {
+	tmp, _ := json.Marshal(map[string]interface{}{name: struct{}{}})
+	return tmp
+}
+
+// newInvocationStep returns the step invoking the reusable command name with args: an *OrbCommandStep if name refers to
+// a command of an orb, as in "node/install-packages", or a *CustomCommandStep otherwise.
+func newInvocationStep(name string, args map[string]interface{}) Step {
+	if orb, command, ok := cut(name, "/"); ok {
+		return &OrbCommandStep{Orb: orb, Command: command, Arguments: args}
+	}
+	return &CustomCommandStep{Command: name, Arguments: args}
+}
+
+// marshalInvocation returns the JSON encoding of the invocation of the command name with args.
+// Without arguments, the invocation is written as the bare name of the command.
+func marshalInvocation(name string, args map[string]interface{}) ([]byte, error) {
+	if args == nil {
+		return json.Marshal(name)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	if tmp, err := json.Marshal(name); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	buf.WriteString(":{")
+	// Marshal the arguments, sorted by key
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if tmp, err := json.Marshal(k); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+		buf.WriteString(":")
+		if tmp, err := json.Marshal(args[k]); err != nil {
+			return nil, err
+		} else {
+			buf.Write(tmp)
+		}
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// CustomCommandStep a step invoking a reusable command of the config.
+type CustomCommandStep struct {
+	// Command the name of the command.
+	Command string
+
+	// Arguments the arguments passed to the parameters of the command. It is nil if the command was invoked by its bare name.
+	Arguments map[string]interface{}
+}
+
+func (r *CustomCommandStep) StepName() string { return r.Command }
+
+func (r *CustomCommandStep) MarshalJSON() ([]byte, error) {
+	return marshalInvocation(r.Command, r.Arguments)
+}
+
+func (r *CustomCommandStep) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+// OrbCommandStep a step invoking a command of an orb, as in "node/install-packages".
+type OrbCommandStep struct {
+	// Orb the alias the orb is imported under.
+	Orb string
+
+	// Command the name of the command within the orb.
+	Command string
+
+	// Arguments the arguments passed to the parameters of the command. It is nil if the command was invoked by its bare name.
+	Arguments map[string]interface{}
+}
+
+func (r *OrbCommandStep) StepName() string { return r.Orb + "/" + r.Command }
+
+func (r *OrbCommandStep) MarshalJSON() ([]byte, error) {
+	return marshalInvocation(r.StepName(), r.Arguments)
+}
+
+func (r *OrbCommandStep) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+// ParameterStep a step expanding a parameter of type steps, as in "<< parameters.after-checkout >>".
+type ParameterStep struct {
+	Expression string
+
+	// Keyed whether the expression is written as the value of a "steps" key, as in `steps: << parameters.after-checkout >>`.
+	Keyed bool
+}
+
+func (r *ParameterStep) StepName() string { return r.Expression }
+
+func (r *ParameterStep) MarshalJSON() ([]byte, error) {
+	if r.Keyed {
+		return json.Marshal(map[string]string{"steps": r.Expression})
+	}
+	return json.Marshal(r.Expression)
+}
+
+func (r *ParameterStep) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+// StepListStep a step written as a list of steps, as in `steps: [checkout]`, which run in its place.
+type StepListStep struct {
+	Steps Steps
+
+	unknownFields
+}
+
+func (r *StepListStep) StepName() string { return "steps" }
+
+func (r *StepListStep) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	// Marshal the "steps" field
+	buf.WriteString("\"steps\": ")
+	tmp, err := json.Marshal(r.Steps)
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(tmp)
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func (r *StepListStep) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+// ConditionalParameters command parameters for the when and unless steps.
+type ConditionalParameters struct {
+	// The condition deciding whether the steps are run.
+	Condition *LogicSchema `json:"condition"`
+
+	// The steps run if the condition holds, for when, or does not hold, for unless.
+	Steps Steps `json:"steps"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *ConditionalParameters) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Condition" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "condition" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"condition\": ")
+	if tmp, err := json.Marshal(r.Condition); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// "Steps" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "steps" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"steps\": ")
+	if tmp, err := json.Marshal(r.Steps); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *ConditionalParameters) UnmarshalJSON(b []byte) error {
+	conditionReceived := false
+	stepsReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "condition":
+			if err := unmarshalValue(v, &r.Condition); err != nil {
+				errs.addAt(k, err)
+			}
+			conditionReceived = true
+		case "steps":
+			if err := unmarshalValue(v, &r.Steps); err != nil {
+				errs.addAt(k, err)
+			}
+			stepsReceived = true
+		default:
+			errs.add(unknownField(k, "condition", "steps"))
+		}
+	}
+	// check if condition (a required property) was received
+	if !conditionReceived {
+		errs.add(missingField("condition"))
+	}
+	// check if steps (a required property) was received
+	if !stepsReceived {
+		errs.add(missingField("steps"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *ConditionalParameters) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *ConditionalParameters) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// WhenCommandSchema json schema for the when step, which runs its steps only if its condition holds.
+type WhenCommandSchema struct {
+	When *ConditionalParameters `json:"when"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *WhenCommandSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "When" field is required
+	if r.When == nil {
+		return nil, errors.New("when is a required field")
+	}
+	// Marshal the "when" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"when\": ")
+	if tmp, err := json.Marshal(r.When); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *WhenCommandSchema) UnmarshalJSON(b []byte) error {
+	whenReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "when":
+			if err := unmarshalValue(v, &r.When); err != nil {
+				errs.addAt(k, err)
+			}
+			whenReceived = true
+		default:
+			errs.add(unknownField(k, "when"))
+		}
+	}
+	// check if when (a required property) was received
+	if !whenReceived {
+		errs.add(missingField("when"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *WhenCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *WhenCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// UnlessCommandSchema json schema for the unless step, which runs its steps only if its condition does not hold.
+type UnlessCommandSchema struct {
+	Unless *ConditionalParameters `json:"unless"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *UnlessCommandSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Unless" field is required
+	if r.Unless == nil {
+		return nil, errors.New("unless is a required field")
+	}
+	// Marshal the "unless" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"unless\": ")
+	if tmp, err := json.Marshal(r.Unless); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *UnlessCommandSchema) UnmarshalJSON(b []byte) error {
+	unlessReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "unless":
+			if err := unmarshalValue(v, &r.Unless); err != nil {
+				errs.addAt(k, err)
+			}
+			unlessReceived = true
+		default:
+			errs.add(unknownField(k, "unless"))
+		}
+	}
+	// check if unless (a required property) was received
+	if !unlessReceived {
+		errs.add(missingField("unless"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *UnlessCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *UnlessCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// DeployCommandSchema json schema for the deploy step, a deprecated form of the run step.
+type DeployCommandSchema struct {
+	Deploy *RunParameters `json:"deploy"`
+
+	unknownFields
+	sourceLayout
+}
+
+func (r *DeployCommandSchema) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Deploy" field is required
+	if r.Deploy == nil {
+		return nil, errors.New("deploy is a required field")
+	}
+	// Marshal the "deploy" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"deploy\": ")
+	if tmp, err := json.Marshal(r.Deploy); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	return r.restoreLayout(buf.Bytes())
+}
+
+func (r *DeployCommandSchema) UnmarshalJSON(b []byte) error {
+	deployReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	r.setKeyOrder(b)
+	var errs Errors
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "deploy":
+			if err := unmarshalValue(v, &r.Deploy); err != nil {
+				errs.addAt(k, err)
+			}
+			deployReceived = true
+		default:
+			errs.add(unknownField(k, "deploy"))
+		}
+	}
+	// check if deploy (a required property) was received
+	if !deployReceived {
+		errs.add(missingField("deploy"))
+	}
+	return r.keepUnknownFields(errs.err())
+}
+
+func (r *DeployCommandSchema) MarshalYAML() (interface{}, error) {
+	return marshalYAML(r)
+}
+
+func (r *DeployCommandSchema) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, r)
+}
+
+// stepPointer returns the JSON pointer to the name of step, the i-th step of the list at the JSON pointer ptr.
+func stepPointer(ptr string, i int, step Step) string {
+	ptr += jsonPointer(strconv.Itoa(i))
+	switch s := step.(type) {
+	case nil, *ParameterStep:
+		return ptr
+	case *CustomCommandStep:
+		if s.Arguments == nil {
+			return ptr
+		}
+	case *OrbCommandStep:
+		if s.Arguments == nil {
+			return ptr
+		}
+	}
+	return ptr + jsonPointer(step.StepName())
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeSteps(t *testing.T) {
+	t.Parallel()
+
+	var steps Steps
+	err := json.Unmarshal([]byte(`[
+		{"run": {"command": "make"}},
+		{"save_cache": {"key": "k", "paths": ["a"]}},
+		{"when": {"condition": true, "steps": [{"checkout": {}}]}},
+		{"greet": {"to": "you"}},
+		{"node/install-packages": {"cache": true}},
+		"<< parameters.after-checkout >>",
+		"greet",
+		{"steps": "<< parameters.after-deps >>"},
+		{"steps": [{"checkout": {}}]}
+	]`), &steps)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := []Step{
+		new(RunCommandSchema),
+		new(SaveCacheCommandSchema),
+		new(WhenCommandSchema),
+		new(CustomCommandStep),
+		new(OrbCommandStep),
+		new(ParameterStep),
+		new(CustomCommandStep),
+		new(ParameterStep),
+		new(StepListStep),
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("decoded %d steps, want %d", len(steps), len(want))
+	}
+	for i, step := range steps {
+		if reflect.TypeOf(step) != reflect.TypeOf(want[i]) {
+			t.Errorf("step %d is a %T, want a %T", i, step, want[i])
+		}
+	}
+
+	if s := steps[3].(*CustomCommandStep); s.Command != "greet" || s.Arguments["to"] != "you" {
+		t.Errorf("step 3 = %+v, want greet invoked with to: you", s)
+	}
+	if s := steps[4].(*OrbCommandStep); s.Orb != "node" || s.Command != "install-packages" {
+		t.Errorf("step 4 = %+v, want install-packages of the node orb", s)
+	}
+	if s := steps[5].(*ParameterStep); s.Expression != "<< parameters.after-checkout >>" {
+		t.Errorf("step 5 = %+v, want the expansion of after-checkout", s)
+	}
+	if s := steps[6].(*CustomCommandStep); s.Command != "greet" || s.Arguments != nil {
+		t.Errorf("step 6 = %+v, want greet invoked without arguments", s)
+	}
+	if s := steps[7].(*ParameterStep); s.Expression != "<< parameters.after-deps >>" || !s.Keyed {
+		t.Errorf("step 7 = %+v, want the keyed expansion of after-deps", s)
+	}
+	if s := steps[8].(*StepListStep); len(s.Steps) != 1 {
+		t.Errorf("step 8 = %+v, want one nested step", s)
+	}
+
+	b, err := json.Marshal(steps)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var again Steps
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatalf("json.Unmarshal() of %s error = %v", b, err)
+	}
+	if !reflect.DeepEqual(again, steps) {
+		t.Errorf("steps changed on a round trip through %s", b)
+	}
+}
+
+func TestDecodeShortSteps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		short string
+		long  string
+	}{
+		{short: `"checkout"`, long: `{"checkout": {}}`},
+		{short: `{"checkout": null}`, long: `{"checkout": {}}`},
+		{short: `{"run": "make test"}`, long: `{"run": {"command": "make test"}}`},
+		{short: `"setup_remote_docker"`, long: `{"setup_remote_docker": {}}`},
+	}
+	for _, tt := range tests {
+		var short, long Steps
+		if err := json.Unmarshal([]byte("["+tt.short+"]"), &short); err != nil {
+			t.Errorf("json.Unmarshal(%s) error = %v", tt.short, err)
+			continue
+		}
+		if err := json.Unmarshal([]byte("["+tt.long+"]"), &long); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", tt.long, err)
+		}
+		// the first field of a built-in step holds its parameters
+		if got, want := reflect.ValueOf(short[0]).Elem().Field(0).Interface(), reflect.ValueOf(long[0]).Elem().Field(0).Interface(); reflect.TypeOf(short[0]) != reflect.TypeOf(long[0]) || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s decoded as %#v, want %#v", tt.short, short[0], long[0])
+		}
+		var want bytes.Buffer
+		json.Compact(&want, []byte("["+tt.short+"]"))
+		if b, err := json.Marshal(short); err != nil || string(b) != want.String() {
+			t.Errorf("json.Marshal() of %s = %s, %v, want the short form", tt.short, b, err)
+		}
+	}
+
+	var changed Steps
+	if err := json.Unmarshal([]byte(`["checkout"]`), &changed); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	changed[0].(*CheckoutCommandSchema).Checkout.Path = "src"
+	if b, _ := json.Marshal(changed); string(b) != `[{"checkout":{"path":"src"}}]` {
+		t.Errorf("json.Marshal() of a changed short step = %s, want its long form", b)
+	}
+
+	var steps Steps
+	if err := json.Unmarshal([]byte(`["greet"]`), &steps); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if s, ok := steps[0].(*CustomCommandStep); !ok || s.Command != "greet" || s.Arguments != nil {
+		t.Errorf("bare command decoded as %#v, want greet without arguments", steps[0])
+	}
+	if b, _ := json.Marshal(steps); string(b) != `["greet"]` {
+		t.Errorf("json.Marshal() = %s, want the bare name", b)
+	}
+}
+
+func TestValidateShortSteps(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "short forms",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - checkout
+      - checkout:
+      - run: make test
+workflows:
+  main:
+    jobs: [build]
+`,
+		},
+		{
+			name: "invalid short forms",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - run: [make]
+      - {checkout: {}, run: make}
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`7:9: error: /jobs/build/steps/0/run: expected string or object but got array`,
+				`8:9: error: /jobs/build/steps/1: a step must have exactly one key, the name of the step`,
+			},
+		},
+	})
+}
+
+func TestValidateRestoreCache(t *testing.T) {
+	runValidateTests(t, &Validator{JSONSchema: true}, []validateTest{
+		{
+			name: "key",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - restore_cache: {key: v1-deps}
+workflows:
+  main:
+    jobs: [build]
+`,
+		},
+		{
+			name: "keys",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - restore_cache: {keys: [v1-deps-main, v1-deps]}
+workflows:
+  main:
+    jobs: [build]
+`,
+		},
+		{
+			name: "key and keys",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - restore_cache: {key: v1-deps, keys: [v1-deps]}
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`7:9: error: /jobs/build/steps/0/restore_cache: "key" and "keys" cannot be used together`,
+			},
+		},
+		{
+			name: "neither key nor keys",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - restore_cache: {name: Restore}
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`7:9: error: /jobs/build/steps/0/restore_cache: one of "key", "keys" is required`,
+			},
+		},
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"sort"
+	"strings"
+)
+
+// suggest returns the candidate closest to name, or the empty string if none is close enough to be a likely misspelling.
+//
+// Candidates are compared case-insensitively, treating "-" and "_" alike. A candidate that starts with name, such as
+// "working_directory" for "working_dir", is always close enough.
+func suggest(name string, candidates []string) string {
+	norm := normalizeName(name)
+	if norm == "" {
+		return ""
+	}
+
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	best, bestDist := "", -1
+	for _, c := range sorted {
+		if c == name {
+			continue
+		}
+		nc := normalizeName(c)
+		dist := levenshtein(norm, nc)
+		if dist > 1 && len(norm) >= 3 && (strings.HasPrefix(nc, norm) || strings.HasPrefix(norm, nc)) {
+			dist = 1
+		}
+		if dist > maxSuggestDistance(norm) {
+			continue
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	return best
+}
+
+// maxSuggestDistance the largest edit distance at which a candidate is still suggested for name.
+func maxSuggestDistance(name string) int {
+	switch {
+	case len(name) <= 3:
+		return 1
+	case len(name)/3 > 2:
+		return len(name) / 3
+	}
+	return 2
+}
+
+func normalizeName(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), "-", "_")
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
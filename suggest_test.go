@@ -0,0 +1,105 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+
+	candidates := []string{"build", "deploy", "working_directory", "resource_class", "lint"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "biuld", want: "build"},
+		{name: "Deploy", want: "deploy"},
+		{name: "working_dir", want: "working_directory"},
+		{name: "resource-class", want: "resource_class"},
+		{name: "lnt", want: "lint"},
+		{name: "test", want: ""},
+		{name: "build", want: ""},
+		{name: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.name, candidates); got != tt.want {
+			t.Errorf("suggest(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// a name equal after normalisation wins over one it is a prefix of
+	if got := suggest("build-test", []string{"build", "build_test"}); got != "build_test" {
+		t.Errorf("suggest(%q) = %q, want %q", "build-test", got, "build_test")
+	}
+}
+
+func TestValidateSuggestions(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "job and command names",
+			config: `
+version: 2.1
+commands:
+  install:
+    steps: [checkout]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [instal]
+workflows:
+  main:
+    jobs:
+      - biuld
+`,
+			want: []string{
+				`9:13: error: /jobs/build/steps/0: invalid value "instal": no such command, did you mean "install"?`,
+				`13:9: error: /workflows/main/jobs/0/biuld: invalid value "biuld": no such job, did you mean "build"?`,
+			},
+		},
+		{
+			name: "requires",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+  deploy:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build
+      - deploy:
+          requires: [biuld, test]
+`,
+			want: []string{
+				`15:22: error: /workflows/main/jobs/1/deploy/requires/0: invalid value "biuld": not a job of workflow "main", did you mean "build"?`,
+				`15:29: error: /workflows/main/jobs/1/deploy/requires/1: invalid value "test": not a job of workflow "main"`,
+			},
+		},
+	})
+	runValidateTests(t, &Validator{DisallowUnknownFields: true}, []validateTest{
+		{
+			name: "unknown keys",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build:
+          filters:
+            branch: {only: main}
+`,
+			want: []string{
+				`12:13: error: /workflows/main/jobs/0/build/filters: unknown field "branch", did you mean "branches"?`,
+			},
+		},
+	})
+}
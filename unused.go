@@ -0,0 +1,267 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// UnusedDefinition a job, reusable command, reusable executor or orb of a config that is not used.
+type UnusedDefinition struct {
+	// Kind the kind of definition: "job", "command", "executor" or "orb".
+	Kind string
+
+	// Name the name of the definition, or the alias of the orb.
+	Name string
+
+	// Path the JSON pointer to the definition, e.g. "/commands/install". See Document.Position for its line and column.
+	Path string
+}
+
+// FindUnused returns the jobs of cfg that no workflow runs, and the reusable commands, reusable executors and orbs that no such
+// job uses, in that order and sorted by name.
+//
+// References are followed through commands, the steps and executors passed as arguments, and the defaults of parameters,
+// so a command used only by an unused job is itself unused. A config without workflows runs its "build" job. If a workflow
+// names a job by a parameter expression, every job is considered run.
+func FindUnused(cfg *CircleCIConfigSchema) []*UnusedDefinition {
+	u := &usage{
+		cfg:       cfg,
+		jobs:      make(map[string]bool),
+		commands:  make(map[string]bool),
+		executors: make(map[string]bool),
+		orbs:      make(map[string]bool),
+	}
+	u.workflows()
+
+	var unused []*UnusedDefinition
+	add := func(kind, section string, names []string, used map[string]bool) {
+		for _, name := range names {
+			if !used[name] {
+				unused = append(unused, &UnusedDefinition{Kind: kind, Name: name, Path: jsonPointer(section, name)})
+			}
+		}
+	}
+	if cfg.Jobs != nil {
+		add("job", "jobs", sortedJobNames(cfg.Jobs), u.jobs)
+	}
+	if cfg.Commands != nil {
+		add("command", "commands", sortedCommandNames(cfg.Commands), u.commands)
+	}
+	if cfg.Executors != nil {
+		add("executor", "executors", sortedExecutorNames(cfg.Executors), u.executors)
+	}
+	if cfg.Orbs != nil {
+		add("orb", "orbs", sortedOrbAliases(cfg.Orbs), u.orbs)
+	}
+	return unused
+}
+
+// usage the definitions of a config found to be used, keyed by name.
+type usage struct {
+	cfg       *CircleCIConfigSchema
+	jobs      map[string]bool
+	commands  map[string]bool
+	executors map[string]bool
+	orbs      map[string]bool
+}
+
+// workflows marks the jobs run by the workflows of the config as used, and what they use.
+func (u *usage) workflows() {
+	if u.cfg.Workflows == nil {
+		u.job("build", nil)
+		return
+	}
+
+	refs := ResolveJobReferences(u.cfg)
+	for _, ref := range refs {
+		if ref.Kind == JobRefParameter && u.cfg.Jobs != nil {
+			for _, name := range sortedJobNames(u.cfg.Jobs) {
+				u.job(name, nil)
+			}
+			break
+		}
+	}
+	for _, ref := range refs {
+		item := u.cfg.Workflows.AdditionalProperties[ref.Workflow].Jobs[ref.Index].AdditionalProperties[ref.Name]
+		var args map[string]interface{}
+		if item != nil {
+			args = item.AdditionalProperties
+			u.steps(item.PreSteps)
+			u.steps(item.PostSteps)
+		}
+		switch ref.Kind {
+		case JobRefDefined:
+			u.job(ref.Name, args)
+		case JobRefOrb:
+			u.orbs[ref.Orb] = true
+			u.orbArguments(ref.Job, args)
+		}
+	}
+}
+
+// job marks the job name, invoked with args, as used, and what it uses.
+func (u *usage) job(name string, args map[string]interface{}) {
+	if u.cfg.Jobs == nil || u.cfg.Jobs.AdditionalProperties[name] == nil {
+		return
+	}
+	job := u.cfg.Jobs.AdditionalProperties[name]
+	u.arguments(job.Parameters, args)
+	if u.jobs[name] {
+		return
+	}
+	u.jobs[name] = true
+	u.defaults(job.Parameters)
+	if job.Executor != nil {
+		u.executor(job.Executor.Name)
+	}
+	u.steps(job.Steps)
+}
+
+// command marks the command name, invoked with args, as used, and what it uses.
+func (u *usage) command(name string, args map[string]interface{}) {
+	if u.cfg.Commands == nil || u.cfg.Commands.AdditionalProperties[name] == nil {
+		return
+	}
+	cmd := u.cfg.Commands.AdditionalProperties[name]
+	u.arguments(cmd.Parameters, args)
+	if u.commands[name] {
+		return
+	}
+	u.commands[name] = true
+	u.defaults(cmd.Parameters)
+	u.steps(cmd.Steps)
+}
+
+// executor marks the executor name as used, or the orb if it is an executor of an orb, such as "node/default".
+func (u *usage) executor(name string) {
+	if alias, _, ok := cut(name, "/"); ok {
+		u.orbs[alias] = true
+		return
+	}
+	u.executors[name] = true
+}
+
+// steps marks the commands and orbs invoked by steps, including nested ones, as used.
+func (u *usage) steps(steps Steps) {
+	for _, step := range steps {
+		switch s := step.(type) {
+		case *CustomCommandStep:
+			u.command(s.Command, s.Arguments)
+		case *OrbCommandStep:
+			u.orbs[s.Orb] = true
+			u.orbArguments(nil, s.Arguments)
+		case *WhenCommandSchema:
+			if s.When != nil {
+				u.steps(s.When.Steps)
+			}
+		case *UnlessCommandSchema:
+			if s.Unless != nil {
+				u.steps(s.Unless.Steps)
+			}
+		case *StepListStep:
+			u.steps(s.Steps)
+		}
+	}
+}
+
+// arguments marks the executors and steps passed by args to the parameters params as used.
+func (u *usage) arguments(params *ParameterSchema, args map[string]interface{}) {
+	for name, v := range args {
+		if decl := lookupParameter(params, name); decl != nil {
+			u.value(decl.Type, v)
+		}
+	}
+}
+
+// defaults marks the executors and steps of the defaults of params as used.
+func (u *usage) defaults(params *ParameterSchema) {
+	if params == nil {
+		return
+	}
+	for _, decl := range params.AdditionalProperties {
+		if decl != nil && decl.Default != nil {
+			u.value(decl.Type, decl.Default)
+		}
+	}
+}
+
+// orbArguments marks the executors and steps passed by args to a job or command of an orb as used. job is the job if it
+// is one of an inline orb.
+//
+// As the parameters of orbs imported from the registry are not known, any list argument is taken to be steps, and any
+// argument naming an executor or a command of the config, or an object whose "name" does, to be that executor or command.
+func (u *usage) orbArguments(job *JobSchemaItem, args map[string]interface{}) {
+	if job != nil {
+		// the executors of an inline orb are its own
+		for name, v := range args {
+			if decl := lookupParameter(job.Parameters, name); decl != nil && decl.Type == "steps" {
+				u.value(decl.Type, v)
+			}
+		}
+		return
+	}
+	for _, v := range args {
+		switch arg := v.(type) {
+		case []interface{}:
+			u.value("steps", arg)
+		case string:
+			u.definition(arg)
+		case map[string]interface{}:
+			if name, ok := arg["name"].(string); ok {
+				u.definition(name)
+			}
+		}
+	}
+}
+
+// definition marks the executor or command name of the config as used, if there is one.
+func (u *usage) definition(name string) {
+	if u.cfg.Executors != nil && u.cfg.Executors.AdditionalProperties[name] != nil {
+		u.executor(name)
+	}
+	if u.cfg.Commands != nil && u.cfg.Commands.AdditionalProperties[name] != nil {
+		u.command(name, nil)
+	}
+}
+
+// value marks the executor or steps v, the value of a parameter of type typ, as used.
+func (u *usage) value(typ string, v interface{}) {
+	switch typ {
+	case "executor":
+		var ref ExecutorReferenceSchema
+		if err := convertValue(v, &ref); err == nil && !isParameterExpression(ref.Name) {
+			u.executor(ref.Name)
+		}
+	case "steps":
+		var steps Steps
+		convertValue(v, &steps)
+		u.steps(steps)
+	}
+}
+
+// convertValue decodes v, a value decoded from JSON, into dst.
+func convertValue(v interface{}, dst interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// checkUnused warns about the jobs, reusable commands, reusable executors and orbs that are not used. See FindUnused.
+// It is run by Validator.WarnUnused.
+func checkUnused(cfg *CircleCIConfigSchema, report *Report) {
+	for _, def := range FindUnused(cfg) {
+		var reason string
+		switch def.Kind {
+		case "job":
+			reason = fmt.Sprintf("job %q is not run by any workflow", def.Name)
+		default:
+			reason = fmt.Sprintf("%s %q is not used by any job that is run", def.Kind, def.Name)
+		}
+		report.add(SeverityWarning, &InvalidValueError{Path: def.Path, Reason: reason})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateWarnUnused(t *testing.T) {
+	runValidateTests(t, &Validator{WarnUnused: true}, []validateTest{
+		{
+			name:   "valid",
+			config: validConfig,
+		},
+		{
+			name: "arguments of registry orbs",
+			config: `
+version: 2.1
+orbs:
+  node: circleci/node@5.0.0
+executors:
+  my-exec:
+    docker: [{image: a}]
+  named-exec:
+    docker: [{image: a}]
+commands:
+  setup:
+    steps: [checkout]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build
+      - node/test:
+          executor: my-exec
+      - node/run:
+          executor: {name: named-exec}
+          setup: setup
+`,
+		},
+		{
+			name: "unused definitions",
+			config: `
+version: 2.1
+orbs:
+  node: circleci/node@5.0.0
+executors:
+  my-exec:
+    docker: [{image: a}]
+commands:
+  setup:
+    steps: [checkout]
+jobs:
+  build:
+    executor: my-exec
+    steps: [checkout]
+  lint:
+    docker: [{image: a}]
+    steps: [setup]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`4:3: warning: /orbs/node: orb "node" is not used by any job that is run`,
+				`9:3: warning: /commands/setup: command "setup" is not used by any job that is run`,
+				`15:3: warning: /jobs/lint: job "lint" is not run by any workflow`,
+			},
+		},
+	})
+}
+
+func TestFindUnused(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodeConfig(t, `
+version: 2.1
+executors:
+  go:
+    docker: [{image: a}]
+  node:
+    docker: [{image: a}]
+commands:
+  lint:
+    steps: [checkout]
+  vet:
+    steps: [checkout]
+  only-unused:
+    steps: [checkout]
+jobs:
+  build:
+    parameters:
+      after: {type: steps, default: [lint]}
+      exec: {type: executor}
+    executor: << parameters.exec >>
+    steps:
+      - checkout
+      - << parameters.after >>
+  unused:
+    executor: node
+    steps: [only-unused]
+workflows:
+  main:
+    jobs:
+      - build:
+          exec: go
+          after: [vet]
+`)
+	var got []string
+	for _, def := range FindUnused(cfg) {
+		got = append(got, def.Kind+" "+def.Path)
+	}
+	want := []string{
+		"job /jobs/unused",
+		"command /commands/only-unused",
+		"executor /executors/node",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("FindUnused() = %q, want %q", got, want)
+	}
+}
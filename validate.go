@@ -0,0 +1,308 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Severity the severity of a Finding.
+type Severity int
+
+const (
+	// SeverityError a problem that makes the config invalid.
+	SeverityError Severity = iota
+
+	// SeverityWarning a problem that does not make the config invalid, but is likely a mistake.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Finding a single problem found in a config.
+type Finding struct {
+	Severity Severity
+
+	// JSON pointer to the node the finding refers to, e.g. "/workflows/main/jobs". The empty string refers to the whole document.
+	Path string
+
+	// Source position of the node at Path, or of its closest ancestor if the node does not exist in the source.
+	Pos Position
+
+	// The problem that was found.
+	Err error
+}
+
+func (f *Finding) String() string {
+	var sb strings.Builder
+	if f.Pos.IsValid() {
+		sb.WriteString(f.Pos.String())
+		sb.WriteString(": ")
+	}
+	sb.WriteString(f.Severity.String())
+	sb.WriteString(": ")
+	sb.WriteString(f.Err.Error())
+	return sb.String()
+}
+
+// Report the result of validating a config.
+type Report struct {
+	// The decoded config. It is nil if the input is not a YAML document, and may be partially populated if decoding failed.
+	Config *CircleCIConfigSchema
+
+	// Every problem found in the config.
+	Findings []*Finding
+}
+
+// Valid reports whether the config has no findings of SeverityError.
+func (r *Report) Valid() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// add adds a finding for err, or one for each of its elements if err is an Errors.
+func (r *Report) add(severity Severity, err error) {
+	if errs, ok := err.(Errors); ok {
+		for _, err := range errs {
+			r.add(severity, err)
+		}
+		return
+	}
+	r.Findings = append(r.Findings, &Finding{
+		Severity: severity,
+		Path:     errorPath(err),
+		Err:      err,
+	})
+}
+
+// addSchemaViolations adds a finding for each violation in err, as returned by ValidateSchema, that no finding of SeverityError reports already.
+//
+// Decoding and the JSON Schema report most problems with the types of values alike, and the checks report some constraints of the schema too.
+// A violation is dropped if one of the first decoded findings, those of decoding, is at or above its path, or if any other error is at its path.
+func (r *Report) addSchemaViolations(err error, decoded int) {
+	errs, ok := err.(Errors)
+	if !ok {
+		r.add(SeverityError, err)
+		return
+	}
+outer:
+	for _, e := range errs {
+		path := errorPath(e)
+		for i, f := range r.Findings {
+			if f.Severity == SeverityError && (f.Path == path || i < decoded && strings.HasPrefix(path, f.Path+"/")) {
+				continue outer
+			}
+		}
+		r.add(SeverityError, e)
+	}
+}
+
+// Validator validates CircleCI configs. The zero value is ready to use.
+type Validator struct {
+	// DisallowUnknownFields reports keys that are not part of a closed object, such as a misspelled "comand" under "run", as errors.
+	DisallowUnknownFields bool
+
+	// JSONSchema additionally validates the config against the JSON Schema maintained with this package. See ValidateSchema.
+	// Violations that decoding or the other checks already report are not reported again.
+	JSONSchema bool
+
+	// WarnUnpinnedOrbs reports orbs that are not pinned to a full semantic version, such as "circleci/node@5" or
+	// "circleci/node@volatile", as warnings.
+	WarnUnpinnedOrbs bool
+
+	// WarnUnused reports jobs that no workflow runs, and reusable commands, reusable executors and orbs that no such job
+	// uses, as warnings. See FindUnused.
+	WarnUnused bool
+}
+
+// Validate reads a .circleci/config.yml from r with the default Validator. See Validator.Validate.
+func Validate(r io.Reader) (*Report, error) {
+	return new(Validator).Validate(r)
+}
+
+// Validate reads a .circleci/config.yml from r, decodes it into a CircleCIConfigSchema and runs every check on it.
+//
+// Problems with the config are reported as findings in the returned Report. The error is non-nil only if r could not be read.
+func (v *Validator) Validate(r io.Reader) (*Report, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := new(Report)
+	doc, err := decodeDocument(b, v.DisallowUnknownFields)
+	if doc == nil {
+		report.add(SeverityError, err)
+		return report, nil
+	}
+	if err != nil {
+		report.add(SeverityError, err)
+	}
+	report.Config = doc.Config
+	decoded := len(report.Findings)
+
+	for _, check := range checkers {
+		check(doc.Config, report)
+	}
+	if v.WarnUnpinnedOrbs {
+		checkOrbPinning(doc.Config, report)
+	}
+	if v.WarnUnused {
+		checkUnused(doc.Config, report)
+	}
+	if v.JSONSchema {
+		if err := ValidateSchema(doc); err != nil {
+			report.addSchemaViolations(err, decoded)
+		}
+	}
+
+	for _, f := range report.Findings {
+		f.Pos, _ = doc.Position(f.Path)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		pi, pj := report.Findings[i].Pos, report.Findings[j].Pos
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+	return report, nil
+}
+
+// checker a semantic check run by Validate on a decoded config. It must tolerate partially decoded configs.
+type checker func(cfg *CircleCIConfigSchema, report *Report)
+
+// checkers every semantic check run by Validate, in order.
+var checkers = []checker{
+	checkVersion,
+	checkWorkflowJobs,
+	checkJobReferences,
+	checkRequires,
+	checkFilters,
+	checkExecutors,
+	checkCommands,
+	checkParameters,
+	checkOrbs,
+}
+
+// checkWorkflowJobs reports workflows that do not run any job.
+func checkWorkflowJobs(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Workflows == nil {
+		return
+	}
+	for _, name := range sortedWorkflowNames(cfg.Workflows) {
+		wf := cfg.Workflows.AdditionalProperties[name]
+		if wf == nil || len(wf.Jobs) == 0 {
+			report.add(SeverityError, &InvalidValueError{
+				Path:   jsonPointer("workflows", name, "jobs"),
+				Reason: fmt.Sprintf("workflow %q must run at least one job", name),
+			})
+		}
+	}
+}
+
+// checkExecutors reports jobs whose "executor" does not name a reusable executor of the config, or passes it invalid arguments.
+//
+// Executors of orbs, such as "node/default", and parameter expressions are not checked.
+func checkExecutors(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Jobs == nil {
+		return
+	}
+	var defined []string
+	if cfg.Executors != nil {
+		for name := range cfg.Executors.AdditionalProperties {
+			defined = append(defined, name)
+		}
+	}
+
+	for _, jobName := range sortedJobNames(cfg.Jobs) {
+		job := cfg.Jobs.AdditionalProperties[jobName]
+		if job == nil || job.Executor == nil {
+			continue
+		}
+		name := job.Executor.Name
+		if strings.Contains(name, "/") || isParameterExpression(name) {
+			continue
+		}
+		ptr := jsonPointer("jobs", jobName, "executor")
+		var exec *ExecutorSchemaItem
+		if cfg.Executors != nil {
+			exec = cfg.Executors.AdditionalProperties[name]
+		}
+		if exec == nil {
+			namePtr := ptr
+			if len(job.Executor.AdditionalProperties) > 0 {
+				namePtr += jsonPointer("name")
+			}
+			report.add(SeverityError, &InvalidValueError{
+				Path:       namePtr,
+				Value:      name,
+				Reason:     "no such executor",
+				Suggestion: suggest(name, defined),
+			})
+			continue
+		}
+		checkArguments(report, ptr, exec.Parameters, job.Executor.AdditionalProperties, nil)
+	}
+}
+
+// isParameterExpression reports whether s contains a parameter expression such as "<< parameters.name >>", whose value is only known once the config is processed.
+func isParameterExpression(s string) bool {
+	i := strings.Index(s, "<<")
+	return i >= 0 && strings.Contains(s[i:], ">>")
+}
+
+func sortedJobNames(s *JobSchema) []string {
+	names := make([]string, 0, len(s.AdditionalProperties))
+	for name := range s.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedWorkflowJobNames(s *WorkflowJobSchema) []string {
+	names := make([]string, 0, len(s.AdditionalProperties))
+	for name := range s.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedWorkflowNames(s *WorkflowSchema) []string {
+	names := make([]string, 0, len(s.AdditionalProperties))
+	for name := range s.AdditionalProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonPointer returns the RFC 6901 JSON pointer made of segments.
+func jsonPointer(segments ...string) string {
+	var sb strings.Builder
+	for _, s := range segments {
+		sb.WriteString("/")
+		sb.WriteString(pointerEscaper.Replace(s))
+	}
+	return sb.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
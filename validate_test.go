@@ -0,0 +1,377 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+// validateTest a config and the findings expected from validating it, as formatted by Finding.String.
+type validateTest struct {
+	name   string
+	config string
+	want   []string
+}
+
+// runValidateTests validates the config of each test with v and compares the findings with the expected ones.
+func runValidateTests(t *testing.T, v *Validator, tests []validateTest) {
+	t.Helper()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := v.Validate(strings.NewReader(tt.config))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			got := make([]string, len(report.Findings))
+			for i, f := range report.Findings {
+				got[i] = f.String()
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("findings:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+			}
+			wantValid := true
+			for _, f := range report.Findings {
+				if f.Severity == SeverityError {
+					wantValid = false
+				}
+			}
+			if report.Valid() != wantValid {
+				t.Errorf("Valid() = %t, want %t", report.Valid(), wantValid)
+			}
+		})
+	}
+}
+
+const validConfig = `
+version: 2.1
+jobs:
+  build:
+    docker:
+      - image: cimg/go:1.17
+    steps:
+      - checkout
+      - run: go test ./...
+workflows:
+  main:
+    jobs:
+      - build
+`
+
+func TestValidate(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name:   "valid",
+			config: validConfig,
+		},
+		{
+			name:   "empty",
+			config: "",
+			want:   []string{"error: config is empty"},
+		},
+		{
+			name:   "not YAML",
+			config: "version: [",
+			want:   []string{"error: yaml: line 1: did not find expected node content"},
+		},
+		{
+			name:   "missing fields",
+			config: "jobs: {}\n",
+			want: []string{
+				`1:1: error: "version" is required but was not present`,
+				`1:1: error: "workflows" is required but was not present`,
+			},
+		},
+		{
+			name: "workflow without jobs",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs: []
+`,
+			want: []string{`9:5: error: /workflows/main/jobs: workflow "main" must run at least one job`},
+		},
+		{
+			name: "findings sorted by position",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - test
+      - build:
+          requires: [lint]
+`,
+			want: []string{
+				`10:9: error: /workflows/main/jobs/0/test: invalid value "test": no such job`,
+				`12:22: error: /workflows/main/jobs/1/build/requires/0: invalid value "lint": not a job of workflow "main"`,
+			},
+		},
+		{
+			name: "wrong types",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  main:
+    jobs:
+      - build:
+          requires: {lint: true}
+`,
+			want: []string{`11:11: error: /workflows/main/jobs/0/build/requires: expected string or array but got object`},
+		},
+	})
+}
+
+func TestValidateJobs(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "parameterised fields",
+			config: `
+version: 2.1
+jobs:
+  build:
+    parameters:
+      ip: {type: boolean, default: false}
+      n: {type: integer, default: 2}
+    docker: [{image: a}]
+    circleci_ip_ranges: << parameters.ip >>
+    parallelism: << parameters.n >>
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+		},
+		{
+			name: "invalid fields",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    circleci_ip_ranges: "yes"
+    parallelism: 0
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`6:5: error: /jobs/build/circleci_ip_ranges: expected boolean but got string`,
+				`7:5: error: /jobs/build/parallelism: invalid value 0: must be at least 1`,
+			},
+		},
+		{
+			name: "parameterised booleans",
+			config: `
+version: 2.1
+jobs:
+  build:
+    parameters:
+      dlc: {type: boolean, default: false}
+    machine: {image: ubuntu-2004:202010-01, docker_layer_caching: << parameters.dlc >>}
+    steps:
+      - run: {command: make serve, background: << parameters.dlc >>}
+  test:
+    parameters:
+      dlc: {type: boolean, default: false}
+    docker: [{image: a}]
+    steps:
+      - setup_remote_docker: {docker_layer_caching: << parameters.dlc >>}
+workflows:
+  main:
+    jobs: [build, test]
+`,
+		},
+		{
+			name: "invalid booleans",
+			config: `
+version: 2.1
+jobs:
+  build:
+    machine: {image: ubuntu-2004:202010-01, docker_layer_caching: "yes"}
+    steps:
+      - run: {command: make serve, background: "yes"}
+  test:
+    docker: [{image: a}]
+    steps:
+      - setup_remote_docker: {docker_layer_caching: 1}
+workflows:
+  main:
+    jobs: [build, test]
+`,
+			want: []string{
+				`5:45: error: /jobs/build/machine/docker_layer_caching: expected boolean but got string`,
+				`7:36: error: /jobs/build/steps/0/run/background: expected boolean but got string`,
+				`11:31: error: /jobs/test/steps/0/setup_remote_docker/docker_layer_caching: expected boolean but got number`,
+			},
+		},
+		{
+			name: "invalid environment, shell and working directory",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    environment: [GOFLAGS=-mod=mod]
+    shell: [bash]
+    working_directory: 1
+    resource_class: large
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`6:19: error: /jobs/build/environment/0: expected object but got string`,
+				`7:5: error: /jobs/build/shell: expected string but got array`,
+				`8:5: error: /jobs/build/working_directory: expected string but got number`,
+			},
+		},
+		{
+			name: "no executor",
+			config: `
+version: 2.1
+jobs:
+  build:
+    steps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{`4:3: error: /jobs/build: one of "docker", "machine", "macos" or "executor" is required`},
+		},
+	})
+}
+
+func TestValidateSteps(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "invalid steps",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - checkout: {path: 1}
+      - {run: {command: make}, save_cache: {key: k, paths: [a]}}
+      - run: {name: test}
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`7:20: error: /jobs/build/steps/0/checkout/path: expected string but got number`,
+				`8:9: error: /jobs/build/steps/1: a step must have exactly one key, the name of the step`,
+				`9:9: error: /jobs/build/steps/2/run: "command" is required but was not present`,
+			},
+		},
+		{
+			name: "undecodable step keeps the index of later steps",
+			config: `
+version: 2.1
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - 42
+      - nosuchcmd
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`7:9: error: /jobs/build/steps/0: expected string or object but got number`,
+				`8:9: error: /jobs/build/steps/1: invalid value "nosuchcmd": no such command`,
+			},
+		},
+		{
+			name: "steps nested under a steps key",
+			config: `
+version: 2.1
+commands:
+  deps:
+    parameters:
+      after-deps: {type: steps, default: []}
+    steps:
+      - run: make deps
+      - steps: << parameters.after-deps >>
+      - steps: [nosuchcmd]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps:
+      - deps:
+          after-deps: [checkout]
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`10:17: error: /commands/deps/steps/2/steps/0: invalid value "nosuchcmd": no such command`,
+			},
+		},
+	})
+}
+
+func TestValidateStepsJSONSchema(t *testing.T) {
+	runValidateTests(t, &Validator{JSONSchema: true}, []validateTest{
+		{
+			name: "steps nested under a steps key",
+			config: `
+version: 2.1
+commands:
+  deps:
+    parameters:
+      after-deps: {type: steps, default: []}
+    steps:
+      - steps: << parameters.after-deps >>
+      - steps: [checkout]
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [deps]
+workflows:
+  main:
+    jobs: [build]
+`,
+		},
+	})
+}
+
+func TestValidateNullJob(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "null job",
+			config: `
+version: 2.1
+jobs:
+  build:
+workflows:
+  main:
+    jobs: [build]
+`,
+			want: []string{
+				`4:3: error: /jobs/build: "steps" is required but was not present`,
+			},
+		},
+	})
+}
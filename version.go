@@ -0,0 +1,147 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// The config versions supported by CircleCI.
+const (
+	ConfigVersion2  = 2
+	ConfigVersion21 = 2.1
+)
+
+// unmarshalVersion decodes b, a version written either as a number or as a string, as in `version: "2.1"`, into version.
+// Whether the version is supported is left to checkVersion.
+func unmarshalVersion(b []byte, version *float64) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch p := v.(type) {
+	case float64:
+		*version = p
+		return nil
+	case string:
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return &InvalidValueError{Value: p, Reason: "not a version number"}
+		}
+		*version = f
+		return nil
+	}
+	return &TypeMismatchError{Expected: "number", Actual: jsonValueType(v)}
+}
+
+// checkVersion reports an unsupported config or workflows version, and features of version 2.1 used by a config of version 2:
+// orbs, reusable commands and executors, pipeline and job parameters, matrix jobs, and when and unless conditions.
+func checkVersion(cfg *CircleCIConfigSchema, report *Report) {
+	if cfg.Workflows != nil && cfg.Workflows.Version != 0 && cfg.Workflows.Version != ConfigVersion2 {
+		report.add(SeverityError, &InvalidValueError{
+			Path:   jsonPointer("workflows", "version"),
+			Value:  cfg.Workflows.Version,
+			Reason: "unsupported workflows version; must be 2",
+		})
+	}
+
+	switch cfg.Version {
+	case 0, ConfigVersion21:
+		// a missing version is reported when decoding
+		return
+	case ConfigVersion2:
+	default:
+		report.add(SeverityError, &InvalidValueError{
+			Path:   jsonPointer("version"),
+			Value:  cfg.Version,
+			Reason: "unsupported version; must be 2 or 2.1",
+		})
+		return
+	}
+
+	// features are named in the plural, as in "orbs" or "matrix jobs"
+	requires21 := func(ptr, features string) {
+		report.add(SeverityError, &InvalidValueError{
+			Path:   ptr,
+			Reason: features + " require version 2.1, but the config is version 2",
+		})
+	}
+	if cfg.Orbs != nil {
+		requires21(jsonPointer("orbs"), "orbs")
+	}
+	if cfg.Commands != nil {
+		requires21(jsonPointer("commands"), "reusable commands")
+	}
+	if cfg.Executors != nil {
+		requires21(jsonPointer("executors"), "reusable executors")
+	}
+	if cfg.Parameters != nil {
+		requires21(jsonPointer("parameters"), "pipeline parameters")
+	}
+
+	if cfg.Jobs != nil {
+		for _, name := range sortedJobNames(cfg.Jobs) {
+			job := cfg.Jobs.AdditionalProperties[name]
+			if job == nil {
+				continue
+			}
+			if job.Parameters != nil {
+				requires21(jsonPointer("jobs", name, "parameters"), "job parameters")
+			}
+			walkConditionalSteps(jsonPointer("jobs", name, "steps"), job.Steps, func(ptr string) {
+				requires21(ptr, "conditional steps")
+			})
+		}
+	}
+
+	if cfg.Workflows == nil {
+		return
+	}
+	for _, wfName := range sortedWorkflowNames(cfg.Workflows) {
+		wf := cfg.Workflows.AdditionalProperties[wfName]
+		if wf == nil {
+			continue
+		}
+		if wf.When != nil {
+			requires21(jsonPointer("workflows", wfName, "when"), "workflow conditions")
+		}
+		if wf.Unless != nil {
+			requires21(jsonPointer("workflows", wfName, "unless"), "workflow conditions")
+		}
+		for i, wfJob := range wf.Jobs {
+			if wfJob == nil {
+				continue
+			}
+			for _, name := range sortedWorkflowJobNames(wfJob) {
+				if item := wfJob.AdditionalProperties[name]; item != nil && item.Matrix != nil {
+					requires21(jsonPointer("workflows", wfName, "jobs", strconv.Itoa(i), name, "matrix"), "matrix jobs")
+				}
+			}
+		}
+	}
+}
+
+// walkConditionalSteps calls fn with the JSON pointer to every when and unless step of steps, found at ptr, including nested ones.
+func walkConditionalSteps(ptr string, steps Steps, fn func(ptr string)) {
+	for i, step := range steps {
+		var cond *ConditionalParameters
+		switch s := step.(type) {
+		case *WhenCommandSchema:
+			cond = s.When
+		case *UnlessCommandSchema:
+			cond = s.Unless
+		case *StepListStep:
+			walkConditionalSteps(stepPointer(ptr, i, step), s.Steps, fn)
+			continue
+		default:
+			continue
+		}
+		stepPtr := stepPointer(ptr, i, step)
+		fn(stepPtr)
+		if cond != nil {
+			walkConditionalSteps(stepPtr+jsonPointer("steps"), cond.Steps, fn)
+		}
+	}
+}
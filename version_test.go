@@ -0,0 +1,92 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"fmt"
+	"testing"
+)
+
+const versionConfig = `
+version: %s
+jobs:
+  build:
+    docker: [{image: a}]
+    steps: [checkout]
+workflows:
+  version: 2
+  main:
+    jobs: [build]
+`
+
+func TestValidateVersion(t *testing.T) {
+	var tests, valid []validateTest
+	for _, tt := range []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{name: "number 2", version: `2`},
+		{name: "number 2.1", version: `2.1`},
+		{name: "string 2", version: `"2"`},
+		{name: "string 2.0", version: `"2.0"`},
+		{name: "string 2.1", version: `"2.1"`},
+		{name: "unsupported number", version: `2.2`, want: `2:1: error: /version: invalid value 2.2: unsupported version; must be 2 or 2.1`},
+		{name: "unsupported string", version: `"3"`, want: `2:1: error: /version: invalid value 3: unsupported version; must be 2 or 2.1`},
+		{name: "not a version", version: `two`, want: `2:1: error: /version: invalid value "two": not a version number`},
+		{name: "wrong type", version: `[2]`, want: `2:1: error: /version: expected number but got array`},
+	} {
+		test := validateTest{name: tt.name, config: fmt.Sprintf(versionConfig, tt.version)}
+		if tt.want != "" {
+			test.want = []string{tt.want}
+		} else {
+			valid = append(valid, test)
+		}
+		tests = append(tests, test)
+	}
+	runValidateTests(t, new(Validator), tests)
+	runValidateTests(t, &Validator{JSONSchema: true}, valid)
+}
+
+func TestValidateVersion21Features(t *testing.T) {
+	runValidateTests(t, new(Validator), []validateTest{
+		{
+			name: "version 2 config using 2.1 features",
+			config: `
+version: 2
+orbs:
+  node: circleci/node@5.0.2
+commands:
+  setup:
+    steps: [checkout]
+jobs:
+  build:
+    parameters:
+      n: {type: integer, default: 1}
+    docker: [{image: a}]
+    steps:
+      - setup
+      - when:
+          condition: true
+          steps: [checkout]
+workflows:
+  version: 3
+  main:
+    jobs:
+      - build:
+          matrix:
+            parameters:
+              n: [1, 2]
+`,
+			want: []string{
+				`3:1: error: /orbs: orbs require version 2.1, but the config is version 2`,
+				`5:1: error: /commands: reusable commands require version 2.1, but the config is version 2`,
+				`10:5: error: /jobs/build/parameters: job parameters require version 2.1, but the config is version 2`,
+				`15:9: error: /jobs/build/steps/1/when: conditional steps require version 2.1, but the config is version 2`,
+				`19:3: error: /workflows/version: invalid value 3: unsupported workflows version; must be 2`,
+				`23:11: error: /workflows/main/jobs/0/build/matrix: matrix jobs require version 2.1, but the config is version 2`,
+			},
+		},
+	})
+}
@@ -0,0 +1,305 @@
+// Copyright 2021 The circleci-validator Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ccivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlToJSON converts a YAML document into the equivalent JSON document so it can be decoded by the UnmarshalJSON methods.
+//
+// Anchors, aliases and merge keys are resolved, and mapping keys are written in source order.
+// The returned map holds the position of every node, keyed by its JSON pointer. A mapping entry is positioned at its key.
+// Empty input is converted to the JSON null literal.
+func yamlToJSON(b []byte) ([]byte, map[string]Position, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, nil, err
+	}
+	if root.Kind == 0 {
+		// empty input
+		return []byte("null"), map[string]Position{}, nil
+	}
+	return nodeToJSON(&root)
+}
+
+// nodeToJSON converts the YAML node n into the equivalent JSON document. See yamlToJSON.
+func nodeToJSON(n *yaml.Node) ([]byte, map[string]Position, error) {
+	c := &yamlConverter{
+		buf:       bytes.NewBuffer(make([]byte, 0)),
+		visiting:  make(map[*yaml.Node]bool),
+		positions: make(map[string]Position),
+	}
+	if err := c.convert(n); err != nil {
+		return nil, nil, err
+	}
+	return c.buf.Bytes(), c.positions, nil
+}
+
+// unmarshalYAML decodes the YAML node value into v by way of its JSON encoding.
+func unmarshalYAML(value *yaml.Node, v interface{}) error {
+	js, _, err := nodeToJSON(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, v)
+}
+
+// marshalYAML returns the YAML node of v by way of its JSON encoding, so that object keys keep the order MarshalJSON writes them in.
+func marshalYAML(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToNode(b)
+}
+
+// jsonToNode converts the JSON document b into a YAML node, keeping the order of object keys.
+//
+// Multi-line strings are written as literal block scalars.
+func jsonToNode(b []byte) (*yaml.Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeNode(dec)
+}
+
+func decodeNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			n = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if n.Kind == yaml.MappingNode {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tok.(string)})
+			}
+			value, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, value)
+		}
+		// consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}
+		if strings.Contains(t, "\n") {
+			n.Style = yaml.LiteralStyle
+		}
+		return n, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// yamlConverter writes a yaml.Node tree as JSON.
+type yamlConverter struct {
+	buf *bytes.Buffer
+
+	// visiting holds the nodes currently being converted, to detect aliases that refer to one of their ancestors.
+	visiting map[*yaml.Node]bool
+
+	// path the JSON pointer segments of the node being converted.
+	path []string
+
+	positions map[string]Position
+}
+
+// record stores the position of n for the current path, unless one was already recorded.
+func (c *yamlConverter) record(n *yaml.Node) {
+	ptr := jsonPointer(c.path...)
+	if _, ok := c.positions[ptr]; !ok {
+		c.positions[ptr] = Position{Line: n.Line, Column: n.Column}
+	}
+}
+
+func (c *yamlConverter) convert(n *yaml.Node) error {
+	if c.visiting[n] {
+		return fmt.Errorf("line %d: recursive alias", n.Line)
+	}
+	c.visiting[n] = true
+	defer delete(c.visiting, n)
+	if n.Kind != yaml.DocumentNode {
+		c.record(n)
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			c.buf.WriteString("null")
+			return nil
+		}
+		return c.convert(n.Content[0])
+	case yaml.MappingNode:
+		pairs, err := mappingPairs(n)
+		if err != nil {
+			return err
+		}
+		c.buf.WriteString("{")
+		for i, p := range pairs {
+			if i > 0 {
+				c.buf.WriteString(",")
+			}
+			tmp, err := json.Marshal(p.key.Value)
+			if err != nil {
+				return err
+			}
+			c.buf.Write(tmp)
+			c.buf.WriteString(":")
+			c.path = append(c.path, p.key.Value)
+			c.record(p.key)
+			if err := c.convert(p.value); err != nil {
+				return err
+			}
+			c.path = c.path[:len(c.path)-1]
+		}
+		c.buf.WriteString("}")
+		return nil
+	case yaml.SequenceNode:
+		c.buf.WriteString("[")
+		for i, item := range n.Content {
+			if i > 0 {
+				c.buf.WriteString(",")
+			}
+			c.path = append(c.path, strconv.Itoa(i))
+			if err := c.convert(item); err != nil {
+				return err
+			}
+			c.path = c.path[:len(c.path)-1]
+		}
+		c.buf.WriteString("]")
+		return nil
+	case yaml.ScalarNode:
+		tmp, err := scalarJSON(n)
+		if err != nil {
+			return err
+		}
+		c.buf.Write(tmp)
+		return nil
+	case yaml.AliasNode:
+		return c.convert(n.Alias)
+	}
+	return fmt.Errorf("line %d: unsupported YAML node kind %d", n.Line, n.Kind)
+}
+
+// yamlPair a single key/value entry of a YAML mapping.
+type yamlPair struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// mappingPairs returns the entries of the mapping n in source order with merge keys ("<<") resolved.
+//
+// Keys written explicitly in n take precedence over merged ones, and earlier merge sources take precedence over later ones.
+func mappingPairs(n *yaml.Node) ([]yamlPair, error) {
+	return mergePairs(n, make(map[*yaml.Node]bool))
+}
+
+func mergePairs(n *yaml.Node, visiting map[*yaml.Node]bool) ([]yamlPair, error) {
+	if visiting[n] {
+		return nil, fmt.Errorf("line %d: recursive merge key", n.Line)
+	}
+	visiting[n] = true
+	defer delete(visiting, n)
+
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i]; !isMergeKey(k) {
+			explicit[k.Value] = true
+		}
+	}
+
+	var pairs []yamlPair
+	merged := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: mapping keys must be scalars", k.Line)
+		}
+		if !isMergeKey(k) {
+			pairs = append(pairs, yamlPair{key: k, value: v})
+			continue
+		}
+
+		sources := []*yaml.Node{v}
+		if resolveAlias(v).Kind == yaml.SequenceNode {
+			sources = resolveAlias(v).Content
+		}
+		for _, src := range sources {
+			src = resolveAlias(src)
+			if src.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: merge key value must be a mapping or a list of mappings", src.Line)
+			}
+			srcPairs, err := mergePairs(src, visiting)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range srcPairs {
+				if explicit[p.key.Value] || merged[p.key.Value] {
+					continue
+				}
+				merged[p.key.Value] = true
+				pairs = append(pairs, p)
+			}
+		}
+	}
+	return pairs, nil
+}
+
+func isMergeKey(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!merge"
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// scalarJSON returns the JSON encoding of the YAML scalar n according to its resolved tag.
+//
+// Scalars that have no JSON equivalent, such as timestamps or .inf, are encoded as strings.
+func scalarJSON(n *yaml.Node) ([]byte, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return []byte("null"), nil
+	case "!!bool", "!!int", "!!float":
+		var v interface{}
+		if err := n.Decode(&v); err == nil {
+			if tmp, err := json.Marshal(v); err == nil {
+				return tmp, nil
+			}
+		}
+	}
+	return json.Marshal(n.Value)
+}